@@ -0,0 +1,136 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bmc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/redhat-cne/sdk-go/pkg/event/redfish"
+)
+
+// DefaultID is the ID of the BMC posting to the /webhook route
+const DefaultID = "default"
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// BMC is a baseboard management controller sending events to the proxy
+type BMC struct {
+	// ID identifies the BMC in the /webhook/{ID} route
+	ID string `json:"id"`
+	// ResourceAddress is the address of the publisher created for the BMC,
+	// e.g. /cluster/node/worker-0/redfish/v1/Chassis
+	ResourceAddress string `json:"resourceAddress"`
+	// Contexts are the subscription contexts the BMC is expected to send,
+	// any context is accepted if empty
+	Contexts []string `json:"contexts,omitempty"`
+	// Redfish is the connection to the Redfish API of the BMC, required
+	// when the proxy reads the events from the BMC instead of the webhook
+	Redfish *Connection `json:"redfish,omitempty"`
+	// SSE enables reading the events from the Redfish Server-Sent Events stream
+	SSE bool `json:"sse,omitempty"`
+	// LogServices are the log service entries polled for BMCs that can't push events,
+	// a path segment can be * to watch all the members of a collection,
+	// e.g. /redfish/v1/Systems/*/LogServices/Sel/Entries
+	LogServices []string `json:"logServices,omitempty"`
+	// PollInterval is the log service poll interval in seconds
+	PollInterval int `json:"pollInterval,omitempty"`
+}
+
+// Config is the content of the BMC configuration file
+type Config struct {
+	BMCs []BMC `json:"bmcs"`
+}
+
+// Resource returns the Redfish part of the resource address, e.g. /redfish/v1/Chassis
+func (b BMC) Resource() string {
+	if i := strings.Index(b.ResourceAddress, "/redfish/"); i >= 0 {
+		return b.ResourceAddress[i:]
+	}
+	return string(redfish.Systems)
+}
+
+// VerifyContext returns true if the context of an event is one of the expected contexts.
+// The top level context is checked, or the deprecated context of every record
+// for BMCs that do not set the top level one.
+func (b BMC) VerifyContext(e redfish.Event) bool {
+	if len(b.Contexts) == 0 {
+		return true
+	}
+	if e.Context != "" || len(e.Events) == 0 {
+		return b.expectsContext(e.Context)
+	}
+	for _, r := range e.Events {
+		if !b.expectsContext(r.Context) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b BMC) expectsContext(context string) bool {
+	for _, c := range b.Contexts {
+		if c == context {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadConfig reads and validates the BMC configuration file
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read BMC config %s: %v", path, err)
+	}
+	config := &Config{}
+	if err = json.Unmarshal(b, config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal BMC config %s: %v", path, err)
+	}
+	if err = config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid BMC config %s: %v", path, err)
+	}
+	return config, nil
+}
+
+// Validate checks that every BMC has a unique ID usable in a route and as a directory name, and a resource address
+func (c *Config) Validate() error {
+	ids := map[string]bool{}
+	for i, b := range c.BMCs {
+		switch {
+		case b.ID == "":
+			return fmt.Errorf("bmcs[%d]: id is required", i)
+		case strings.ContainsAny(b.ID, "/?#"):
+			return fmt.Errorf("bmcs[%d]: id %q must not contain '/', '?' or '#'", i, b.ID)
+		case b.ID == "." || b.ID == "..":
+			// the ID names the store directories of the BMC
+			return fmt.Errorf("bmcs[%d]: id %q is not a valid directory name", i, b.ID)
+		case b.ID == DefaultID:
+			return fmt.Errorf("bmcs[%d]: id %q is reserved for the /webhook route", i, b.ID)
+		case ids[b.ID]:
+			return fmt.Errorf("bmcs[%d]: duplicate id %q", i, b.ID)
+		case b.ResourceAddress == "":
+			return fmt.Errorf("bmcs[%d]: resourceAddress is required", i)
+		case b.SSE && (b.Redfish == nil || b.Redfish.Address == ""):
+			return fmt.Errorf("bmcs[%d]: redfish.address is required for sse", i)
+		case len(b.LogServices) > 0 && (b.Redfish == nil || b.Redfish.Address == ""):
+			return fmt.Errorf("bmcs[%d]: redfish.address is required for logServices", i)
+		}
+		ids[b.ID] = true
+	}
+	return nil
+}
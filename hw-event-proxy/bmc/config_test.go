@@ -0,0 +1,41 @@
+//go:build unittests
+// +build unittests
+
+package bmc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	redfish := &Connection{Address: "https://10.0.0.1"}
+	for _, tc := range []struct {
+		name string
+		bmcs []BMC
+		err  string
+	}{
+		{"valid", []BMC{{ID: "bmc-1", ResourceAddress: "/cluster/node/worker-1/redfish/v1/Systems"},
+			{ID: "bmc-2", ResourceAddress: "/cluster/node/worker-2/redfish/v1/Systems", Redfish: redfish, SSE: true}}, ""},
+		{"missing id", []BMC{{ResourceAddress: "/a"}}, "id is required"},
+		{"id with slash", []BMC{{ID: "a/b", ResourceAddress: "/a"}}, "must not contain"},
+		{"dot id", []BMC{{ID: ".", ResourceAddress: "/a"}}, "not a valid directory name"},
+		{"dot dot id", []BMC{{ID: "..", ResourceAddress: "/a"}}, "not a valid directory name"},
+		{"default id", []BMC{{ID: DefaultID, ResourceAddress: "/a"}}, "reserved"},
+		{"duplicate id", []BMC{{ID: "a", ResourceAddress: "/a"}, {ID: "a", ResourceAddress: "/b"}}, "duplicate id"},
+		{"missing resource address", []BMC{{ID: "a"}}, "resourceAddress is required"},
+		{"sse without redfish", []BMC{{ID: "a", ResourceAddress: "/a", SSE: true}}, "redfish.address is required for sse"},
+		{"log services without redfish", []BMC{{ID: "a", ResourceAddress: "/a", LogServices: []string{"/log"}}},
+			"redfish.address is required for logServices"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := (&Config{BMCs: tc.bmcs}).Validate()
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bmc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Connection holds the address and credentials of the Redfish API of a BMC
+type Connection struct {
+	// Address is the host[:port] of the BMC, https is used unless a scheme is given
+	Address string `json:"address"`
+	// UsernameFile and PasswordFile are files holding the credentials,
+	// typically mounted from a secret
+	UsernameFile string `json:"usernameFile,omitempty"`
+	PasswordFile string `json:"passwordFile,omitempty"`
+	// InsecureSkipVerify disables the verification of the BMC certificate
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	Username string `json:"-"`
+	Password string `json:"-"`
+}
+
+// LoadCredentials reads the username and password files
+func (c *Connection) LoadCredentials() error {
+	if c.UsernameFile != "" {
+		b, err := os.ReadFile(c.UsernameFile)
+		if err != nil {
+			return fmt.Errorf("failed to read username file: %v", err)
+		}
+		c.Username = strings.TrimSpace(string(b))
+	}
+	if c.PasswordFile != "" {
+		b, err := os.ReadFile(c.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("failed to read password file: %v", err)
+		}
+		c.Password = strings.TrimSpace(string(b))
+	}
+	return nil
+}
+
+// URL returns the URL of a Redfish path, e.g. /redfish/v1/EventService
+func (c *Connection) URL(path string) string {
+	if strings.Contains(c.Address, "://") {
+		return strings.TrimSuffix(c.Address, "/") + path
+	}
+	return "https://" + c.Address + path
+}
+
+// HTTPClient returns a client for the BMC, a zero timeout is used for streams
+func (c *Connection) HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: c.InsecureSkipVerify, //nolint:gosec
+				MinVersion:         tls.VersionTLS12,
+			},
+			ResponseHeaderTimeout: 30 * time.Second,
+		},
+	}
+}
+
+// NewRequest creates an authenticated request for a Redfish path
+func (c *Connection) NewRequest(ctx context.Context, method, path string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.URL(path), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// Get reads a Redfish resource into out
+func (c *Connection) Get(ctx context.Context, client *http.Client, path string, out interface{}) error {
+	req, err := c.NewRequest(ctx, http.MethodGet, path)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get %s returned status %d", path, resp.StatusCode)
+	}
+	if err = json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %v", path, err)
+	}
+	return nil
+}
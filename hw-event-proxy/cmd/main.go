@@ -16,15 +16,20 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	jsoniter "github.com/json-iterator/go"
 
 	"github.com/redhat-cne/sdk-go/pkg/event"
@@ -33,11 +38,19 @@ import (
 	"github.com/redhat-cne/sdk-go/pkg/types"
 	"github.com/redhat-cne/sdk-go/pkg/util/wait"
 
-	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/pb"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/bmc"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/eventrecord"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/logservice"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/msgcache"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/outbox"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/parser"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/queue"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/registry"
 	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/restclient"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/sse"
 	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/util"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/validation"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/webhook"
 
 	v1event "github.com/redhat-cne/sdk-go/v1/event"
 	v1pubsub "github.com/redhat-cne/sdk-go/v1/pubsub"
@@ -49,18 +62,97 @@ const (
 	// in seconds
 	publisherRetryInterval = 5
 	webhookRetryInterval   = 5
+	registryRetryInterval  = 60
+	// time allowed to read the request headers
+	webhookReadHeaderTimeout = 10
+
+	contextPolicyReject = "reject"
+	contextPolicyTag    = "tag"
+	// unexpectedContextTag is added to the values of events with an unexpected subscription context
+	unexpectedContextTag = "UnexpectedContext"
+	// enrichment policies of the records with the registry messages
+	enrichmentNever = "never"
+	enrichmentEmpty = "empty"
+	enrichmentFill  = "fill"
+	// number of unresolved MessageIds listed by /registries/unresolved
+	unresolvedMessageIDs = 100
 )
 
 var (
 	apiPath          = "/api/ocloudNotifications/v1/"
 	apiPort          int
 	json             = jsoniter.ConfigCompatibleWithStandardLibrary
-	pub              pubsub.PubSub
-	resourceAddress  string
 	baseURL          *types.URI
 	msgParserPort    = util.GetIntEnv("MSG_PARSER_PORT", 9097)
 	hwEventPort      = util.GetIntEnv("HW_EVENT_PROXY_SERVICE_SERVICE_PORT", 9087)
 	msgParserTimeout = time.Duration(util.GetIntEnv("MSG_PARSER_TIMEOUT", 10)) * time.Millisecond
+	// maximum size in bytes of a hw event accepted by the webhook
+	maxEventSize = int64(util.GetIntEnv("HW_EVENT_MAX_SIZE", 1048576))
+	// number of events waiting to be published, 0 disables the queue
+	eventQueueSize = util.GetIntEnv("HW_EVENT_QUEUE_SIZE", 100)
+	eventWorkers   = util.GetIntEnv("HW_EVENT_WORKERS", 1)
+	eventQueue     *queue.Queue
+	// how events violating the Redfish Event schema are handled
+	validationMode = validation.Warn
+	// what happens to events with an unexpected subscription context, reject or tag
+	contextPolicy = util.GetStringEnv("HW_EVENT_CONTEXT_POLICY", contextPolicyReject)
+	// number of events received with an unexpected subscription context
+	contextMismatches uint64
+	// derive the UniqueId of a Redfish event from its Id and the RecordIds of its records instead of a random UUID
+	deterministicIDs = util.GetStringEnv("HW_EVENT_DETERMINISTIC_ID", "false") == "true"
+	// publish one cloud event per record instead of one per Redfish event
+	splitEvents = util.GetStringEnv("HW_EVENT_SPLIT", "false") == "true"
+	// in seconds, the BMC clock is skewed when the EventTimestamp of a pushed event is further
+	// from the receive time, 0 disables the check
+	clockSkewThreshold = time.Duration(util.GetIntEnv("HW_EVENT_CLOCK_SKEW", 300)) * time.Second
+	// number of pushed events received from a BMC with a skewed clock
+	clockSkews uint64
+	// which records are enriched with the registry messages, never, empty or fill
+	enrichmentPolicy = util.GetStringEnv("HW_EVENT_ENRICHMENT", enrichmentEmpty)
+	// directory where the proxy persists its state
+	storePath = util.GetStringEnv("HW_EVENT_STORE_PATH", "/store")
+	// events persisted per BMC until they are published, 0 publishes the events without outbox
+	outboxSize = util.GetIntEnv("HW_EVENT_OUTBOX_SIZE", 1000)
+	// in seconds, older events are dropped from the outbox
+	outboxMaxAge = util.GetIntEnv("HW_EVENT_OUTBOX_MAX_AGE", 86400)
+	// drop the events the sidecar rejects with a 4xx status that retrying doesn't fix
+	outboxDropRejected = util.GetStringEnv("HW_EVENT_OUTBOX_DROP_REJECTED", "false") == "true"
+	eventOutbox        *outbox.Outbox
+	// client of the sidecar API, the timeout in seconds applies to every attempt of a request
+	restClient = restclient.New(time.Duration(util.GetIntEnv("HW_EVENT_HTTP_TIMEOUT", 2)) * time.Second)
+	// default log service poll interval in seconds
+	pollInterval = util.GetIntEnv("HW_EVENT_POLL_INTERVAL", 60)
+	// message registry bundle resolved in-process, a directory, a tarball or a JSON file,
+	// messages are parsed by the message parser sidecar when empty
+	registryPath = os.Getenv("HW_EVENT_REGISTRY_PATH")
+	// merge the registries hosted by the BMCs into the bundle once they are reachable
+	registryFromBMC = util.GetStringEnv("HW_EVENT_REGISTRY_FROM_BMC", "false") == "true"
+	// interval in seconds between downloads of the BMC registries, 0 downloads them once
+	registryRefreshInterval = util.GetIntEnv("HW_EVENT_REGISTRY_REFRESH_INTERVAL", 86400)
+	// messageRegistry resolves the message of records without one when registryPath or registryFromBMC is set
+	messageRegistry *registry.Resolver
+	// msgCache caches the parsed messages, nil when HW_EVENT_MESSAGE_CACHE_SIZE is 0
+	msgCache = newMessageCache()
+	// unresolvedMessages counts the recent MessageIds not found in the registries or with invalid arguments
+	unresolvedMessages = registry.NewUnresolved(unresolvedMessageIDs)
+	// msgParser is the client of the message parser sidecar, used when messageRegistry is not set
+	msgParser *parser.Client
+	// credentials required from BMCs posting to the webhook
+	authenticator = webhook.NewAuthenticator(os.Getenv("WEBHOOK_BASIC_AUTH_FILE"), os.Getenv("WEBHOOK_BEARER_TOKEN_FILE"))
+
+	// publishers created for the BMCs, keyed by BMC ID
+	publishers = map[string]*bmcPublisher{
+		bmc.DefaultID: {BMC: bmc.BMC{ID: bmc.DefaultID}},
+	}
+	publishersLock sync.RWMutex
+
+	errInvalidEvent         = errors.New("failed to unmarshal hw event")
+	errUnknownBMC           = errors.New("unknown BMC")
+	errEventValidation      = errors.New("hw event failed validation")
+	errUnexpectedContext    = errors.New("unexpected subscription context")
+	errPublisherUnavailable = errors.New("publisher is not available")
+	errPublishFailed        = errors.New("failed to publish hw event")
+	errQueueFull            = errors.New("hw event queue is full")
 )
 
 func main() {
@@ -74,7 +166,67 @@ func main() {
 		nodeName = "mock"
 	}
 
-	resourceAddress = fmt.Sprintf("/cluster/node/%s%s", nodeName, string(redfish.Systems))
+	if mode, err := validation.ParseMode(util.GetStringEnv("HW_EVENT_VALIDATION", string(validation.Warn))); err == nil {
+		validationMode = mode
+	} else {
+		log.Errorf("%v, falling back to %s", err, validation.Warn)
+	}
+	log.Infof("hw event validation mode %s", validationMode)
+	if contextPolicy != contextPolicyReject && contextPolicy != contextPolicyTag {
+		log.Errorf("unknown context policy %q, falling back to %s", contextPolicy, contextPolicyReject)
+		contextPolicy = contextPolicyReject
+	}
+	if enrichmentPolicy != enrichmentNever && enrichmentPolicy != enrichmentEmpty && enrichmentPolicy != enrichmentFill {
+		log.Errorf("unknown enrichment policy %q, falling back to %s", enrichmentPolicy, enrichmentEmpty)
+		enrichmentPolicy = enrichmentEmpty
+	}
+
+	if registryPath != "" || registryFromBMC {
+		messageRegistry = registry.NewResolver()
+	}
+	if registryPath != "" {
+		count, err := messageRegistry.Load(registryPath)
+		if err != nil {
+			log.Fatalf("failed to load message registries from %s: %v", registryPath, err)
+		}
+		log.Infof("loaded %d message registries from %s", count, registryPath)
+	}
+	if messageRegistry == nil {
+		client, err := parser.NewClient(fmt.Sprintf("localhost:%d", msgParserPort), msgParserTimeout)
+		if err != nil {
+			log.Fatalf("failed to create message parser client: %v", err)
+		}
+		msgParser = client
+		go msgParser.Run(wait.NeverStop)
+	}
+
+	bmcs := []bmc.BMC{{
+		ID:              bmc.DefaultID,
+		ResourceAddress: fmt.Sprintf("/cluster/node/%s%s", nodeName, string(redfish.Systems)),
+		Contexts:        splitList(os.Getenv("HW_EVENT_EXPECTED_CONTEXTS")),
+		SSE:             util.GetStringEnv("HW_EVENT_SSE", "false") == "true",
+		LogServices:     splitList(os.Getenv("HW_EVENT_LOG_SERVICES")),
+	}}
+	if hostAddr := os.Getenv("REDFISH_HOSTADDR"); hostAddr != "" {
+		bmcs[0].Redfish = &bmc.Connection{
+			Address:            hostAddr,
+			Username:           os.Getenv("REDFISH_USERNAME"),
+			Password:           os.Getenv("REDFISH_PASSWORD"),
+			InsecureSkipVerify: util.GetStringEnv("REDFISH_INSECURE_SKIP_VERIFY", "true") == "true",
+		}
+	} else if bmcs[0].SSE || len(bmcs[0].LogServices) > 0 {
+		log.Fatal("REDFISH_HOSTADDR is required when HW_EVENT_SSE or HW_EVENT_LOG_SERVICES is set")
+	}
+	if configFile := os.Getenv("BMC_CONFIG_FILE"); configFile != "" {
+		config, err := bmc.LoadConfig(configFile)
+		if err != nil {
+			log.Fatalf("failed to load BMC config: %v", err)
+		}
+		bmcs = append(bmcs, config.BMCs...)
+	}
+	for _, b := range bmcs {
+		publishers[b.ID] = &bmcPublisher{BMC: b}
+	}
 	baseURL = types.ParseURI(fmt.Sprintf("http://localhost:%d%s", apiPort, apiPath))
 
 	// check sidecar api health
@@ -89,17 +241,40 @@ func main() {
 
 	// TODO: if publisher fails it should be os.Exit(1)
 	var err error
-	for {
-		pub, err = createPublisher()
-		if err != nil {
-			log.Errorf("error creating publisher: %s\n, will retry in %d seconds", err.Error(), publisherRetryInterval)
-		} else {
-			break
+	ctx := context.Background()
+	for _, b := range bmcs {
+		var pub pubsub.PubSub
+		for {
+			pub, err = createPublisher(ctx, b.ResourceAddress)
+			if err != nil {
+				log.Errorf("error creating publisher for BMC %s: %s\n, will retry in %d seconds", b.ID, err.Error(), publisherRetryInterval)
+			} else {
+				break
+			}
+			time.Sleep(publisherRetryInterval * time.Second)
 		}
-		time.Sleep(publisherRetryInterval * time.Second)
+		setPublisher(b.ID, pub)
+		log.Infof("Created publisher %v for BMC %s", pub, b.ID)
 	}
 
-	log.Infof("Created publisher %v", pub)
+	if outboxSize > 0 {
+		startOutbox()
+	}
+	if eventQueueSize > 0 {
+		var policy queue.OverflowPolicy
+		policy, err = queue.ParseOverflowPolicy(util.GetStringEnv("HW_EVENT_QUEUE_OVERFLOW", string(queue.Reject)))
+		if err != nil {
+			log.Errorf("%v, falling back to %s", err, queue.Reject)
+			policy = queue.Reject
+		}
+		eventQueue = queue.New(eventQueueSize, eventWorkers, policy)
+		eventQueue.Start(wait.NeverStop)
+		log.Infof("event queue size %d, workers %d, overflow policy %s", eventQueueSize, eventWorkers, policy)
+	}
+	startIngestors(bmcs)
+	if registryFromBMC {
+		startRegistryDownloads(bmcs)
+	}
 	var wg sync.WaitGroup
 	wg.Add(1)
 	startWebhook(&wg, hwEventPort)
@@ -107,7 +282,134 @@ func main() {
 	wg.Wait()
 }
 
-func createPublisher() (pub pubsub.PubSub, err error) {
+// startOutbox starts publishing the events persisted in the store, including the events
+// left by a previous run. The events are published without outbox when the store is not writable.
+func startOutbox() {
+	o, err := outbox.New(filepath.Join(storePath, "outbox"), outboxSize, time.Duration(outboxMaxAge)*time.Second, postHwEvent)
+	if err == nil {
+		o.DropRejected = outboxDropRejected
+		err = o.Start(wait.NeverStop)
+	}
+	if err != nil {
+		log.Errorf("error creating the event outbox, events are not persisted: %v", err)
+		return
+	}
+	eventOutbox = o
+	log.Infof("event outbox size %d, max age %d seconds", outboxSize, outboxMaxAge)
+}
+
+// startRegistryDownloads merges the message registries hosted by the BMCs into the loaded registries.
+// The registries cached in the store are merged once the BMC is verified to be the one they were
+// downloaded from, then the registries are downloaded in the background, retrying until the BMC is
+// reachable, and refreshed every registryRefreshInterval.
+func startRegistryDownloads(bmcs []bmc.BMC) {
+	for _, b := range bmcs {
+		if b.Redfish == nil {
+			continue
+		}
+		b := b
+		cache := registry.NewCache(storePath, b.ID)
+		go func() {
+			if err := b.Redfish.LoadCredentials(); err != nil {
+				log.Errorf("failed to load Redfish credentials of BMC %s: %v", b.ID, err)
+				return
+			}
+			downloader := registry.NewDownloader(b.Redfish)
+			for {
+				identity, err := downloader.Identity(context.Background())
+				if err == nil {
+					mergeCachedRegistries(b.ID, identity, cache)
+					break
+				}
+				log.Warnf("failed to read the identity of BMC %s: %v, will retry in %d seconds", b.ID, err, registryRetryInterval)
+				time.Sleep(registryRetryInterval * time.Second)
+			}
+			for {
+				if err := downloadRegistries(b.ID, downloader, cache); err != nil {
+					log.Warnf("failed to download message registries from BMC %s: %v, will retry in %d seconds", b.ID, err, registryRetryInterval)
+					time.Sleep(registryRetryInterval * time.Second)
+					continue
+				}
+				if registryRefreshInterval <= 0 {
+					return
+				}
+				time.Sleep(time.Duration(registryRefreshInterval) * time.Second)
+			}
+		}()
+	}
+}
+
+// mergeCachedRegistries merges the cached registries of a BMC when they were downloaded from the BMC with identity,
+// which is the UUID of its Redfish service, so that the registries of a replaced BMC are not used
+func mergeCachedRegistries(bmcID, identity string, cache *registry.Cache) {
+	cachedIdentity, cached, err := cache.Load()
+	switch {
+	case err != nil:
+		log.Warnf("failed to load the cached message registries of BMC %s: %v", bmcID, err)
+	case cachedIdentity == "":
+	case cachedIdentity != identity:
+		log.Infof("ignored the cached message registries of BMC %s downloaded from %s, the BMC is %s", bmcID, cachedIdentity, identity)
+	default:
+		for _, r := range cached {
+			messageRegistry.Add(r)
+		}
+		log.Infof("merged %d cached message registries of BMC %s", len(cached), bmcID)
+	}
+}
+
+// downloadRegistries merges the registries of a BMC and saves them in its cache
+func downloadRegistries(bmcID string, downloader *registry.Downloader, cache *registry.Cache) error {
+	ctx := context.Background()
+	identity, err := downloader.Identity(ctx)
+	if err != nil {
+		return err
+	}
+	registries, err := downloader.Download(ctx)
+	if err != nil {
+		return err
+	}
+	for _, r := range registries {
+		messageRegistry.Add(r)
+	}
+	if msgCache != nil {
+		msgCache.Purge()
+	}
+	log.Infof("merged %d message registries from BMC %s", len(registries), bmcID)
+	if err = cache.Save(identity, registries); err != nil {
+		log.Errorf("failed to cache the message registries of BMC %s: %v", bmcID, err)
+	}
+	return nil
+}
+
+// bmcPublisher is the publisher created for the events of a BMC
+type bmcPublisher struct {
+	bmc.BMC
+	pub pubsub.PubSub
+	// clockSkewed is set while the EventTimestamps of the BMC are skewed
+	clockSkewed atomic.Bool
+}
+
+// getPublisher returns the publisher of a BMC, once it has been created
+func getPublisher(bmcID string) (*bmcPublisher, error) {
+	publishersLock.RLock()
+	defer publishersLock.RUnlock()
+	p, ok := publishers[bmcID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errUnknownBMC, bmcID)
+	}
+	if p.pub.ID == "" {
+		return nil, fmt.Errorf("%w for BMC %q", errPublisherUnavailable, bmcID)
+	}
+	return p, nil
+}
+
+func setPublisher(bmcID string, pub pubsub.PubSub) {
+	publishersLock.Lock()
+	defer publishersLock.Unlock()
+	publishers[bmcID].pub = pub
+}
+
+func createPublisher(ctx context.Context, resourceAddress string) (pub pubsub.PubSub, err error) {
 	publisherURL := types.ParseURI(fmt.Sprintf("%s%s", baseURL, "publishers"))
 	returnURL := types.ParseURI(fmt.Sprintf("%s%s", baseURL, "dummy"))
 	publisher := v1pubsub.NewPubSub(returnURL, resourceAddress)
@@ -115,8 +417,10 @@ func createPublisher() (pub pubsub.PubSub, err error) {
 	var pubB []byte
 	var status int
 	if pubB, err = json.Marshal(&publisher); err == nil {
-		rc := restclient.New()
-		if status, pubB = rc.PostWithReturn(publisherURL, pubB); status != http.StatusCreated {
+		if status, pubB, err = restClient.PostWithRetry(ctx, publisherURL, pubB); err != nil {
+			return pub, fmt.Errorf("failed to create publisher: %w", err)
+		}
+		if status != http.StatusCreated {
 			err = fmt.Errorf("failed to create publisher creation api at %s, returned status %d", publisherURL, status)
 			return pub, err
 		}
@@ -131,12 +435,102 @@ func createPublisher() (pub pubsub.PubSub, err error) {
 	return pub, nil
 }
 
+// startIngestors starts reading the events of the BMCs that don't post them to the webhook
+func startIngestors(bmcs []bmc.BMC) {
+	for _, b := range bmcs {
+		if !b.SSE && len(b.LogServices) == 0 {
+			continue
+		}
+		bmcID := b.ID
+		if err := b.Redfish.LoadCredentials(); err != nil {
+			log.Errorf("failed to load Redfish credentials of BMC %s: %v", bmcID, err)
+			continue
+		}
+		if b.SSE {
+			client := sse.NewClient(bmcID, b.Redfish, func(ctx context.Context, e eventrecord.Event) {
+				if err := ingestHwEvent(ctx, bmcID, e); err != nil {
+					log.Errorf("error handling hw event from BMC %s: %v", bmcID, err)
+				}
+			})
+			log.Infof("reading events of BMC %s from the SSE stream of %s", bmcID, b.Redfish.Address)
+			go client.Run(wait.NeverStop)
+		}
+		if len(b.LogServices) > 0 {
+			interval := b.PollInterval
+			if interval <= 0 {
+				interval = pollInterval
+			}
+			poller := logservice.NewPoller(bmcID, b.Redfish, b.LogServices, time.Duration(interval)*time.Second, storePath, pollHandler(b))
+			log.Infof("polling log services %v of BMC %s every %d seconds", b.LogServices, bmcID, interval)
+			go poller.Run(wait.NeverStop)
+		}
+	}
+}
+
+// pollHandler publishes the entries read from the log services of a BMC
+func pollHandler(b bmc.BMC) logservice.Handler {
+	bmcID := b.ID
+	// events read from the log services carry no subscription context
+	var subscriptionContext string
+	if len(b.Contexts) > 0 {
+		subscriptionContext = b.Contexts[0]
+	}
+	return func(ctx context.Context, e eventrecord.Event) error {
+		e.Redfish.Context = subscriptionContext
+		err := pollHwEvent(ctx, bmcID, e)
+		if errors.Is(err, errEventValidation) {
+			// reading the entries again doesn't make them valid
+			log.Errorf("dropped hw event from BMC %s: %v", bmcID, err)
+			return nil
+		}
+		return err
+	}
+}
+
 func startWebhook(wg *sync.WaitGroup, port int) {
 	http.HandleFunc("/ack/event", ackEvent)
-	http.HandleFunc("/webhook", webhook)
+	if authenticator.Enabled() {
+		log.Info("webhook authentication is enabled")
+	} else {
+		log.Warn("webhook authentication is disabled, any client can post events")
+	}
+	http.HandleFunc("/webhook", authenticator.Wrap(webhookHandler))
+	http.HandleFunc("/webhook/", authenticator.Wrap(webhookHandler))
+	// the diagnostics expose the registries of the BMCs and the MessageIds they send
+	http.HandleFunc("/stats", authenticator.Wrap(statsHandler))
+	http.HandleFunc("/registries", authenticator.Wrap(registriesHandler))
+	http.HandleFunc("/registries/resolve", authenticator.Wrap(resolveMessageHandler))
+	http.HandleFunc("/registries/unresolved", authenticator.Wrap(unresolvedHandler))
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		ReadHeaderTimeout: webhookReadHeaderTimeout * time.Second,
+	}
+	certFile := os.Getenv("WEBHOOK_TLS_CERT_FILE")
+	keyFile := os.Getenv("WEBHOOK_TLS_KEY_FILE")
+	if certFile != "" || keyFile != "" {
+		clientCAFile := os.Getenv("WEBHOOK_TLS_CLIENT_CA_FILE")
+		reloader, err := webhook.NewCertReloader(certFile, keyFile, clientCAFile)
+		if err != nil {
+			log.Fatalf("failed to load webhook certificates: %v", err)
+		}
+		reloader.Watch(time.Duration(util.GetIntEnv("WEBHOOK_TLS_RELOAD_INTERVAL", 30))*time.Second, wait.NeverStop)
+		server.TLSConfig = reloader.TLSConfig()
+		if clientCAFile != "" {
+			log.Infof("webhook is serving TLS and requires client certificates signed by %s", clientCAFile)
+		} else {
+			log.Info("webhook is serving TLS")
+		}
+	}
+
 	go wait.Until(func() {
 		defer wg.Done()
-		err := http.ListenAndServe(fmt.Sprintf(":%d", port), nil)
+		var err error
+		if server.TLSConfig != nil {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
 		if err != nil {
 			log.Errorf("error starting webhook: %s\n, will retry in %d seconds", err.Error(), webhookRetryInterval)
 		}
@@ -157,106 +551,698 @@ func ackEvent(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
-func webhook(_ http.ResponseWriter, r *http.Request) {
+// webhookHandler receives events posted to /webhook by the default BMC
+// and to /webhook/{bmcID} by the BMCs listed in the BMC config
+func webhookHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
-	bodyBytes, err := io.ReadAll(r.Body)
+	bmcID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/webhook"), "/")
+	if bmcID == "" {
+		bmcID = bmc.DefaultID
+	}
+	bodyBytes, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEventSize))
 	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			log.Errorf("hw event exceeds the maximum size of %d bytes", maxEventSize)
+			webhook.WriteError(w, http.StatusRequestEntityTooLarge, webhook.GeneralError,
+				fmt.Sprintf("The event exceeds the maximum size of %d bytes.", maxEventSize),
+				"Reduce the number of records in the event and resubmit the request.")
+			return
+		}
 		log.Errorf("error reading hw event: %v", err)
+		webhook.WriteError(w, http.StatusBadRequest, webhook.GeneralError,
+			"The event could not be read.", "Resubmit the request.")
+		return
+	}
+	if eventQueue == nil {
+		if err = handleHwEvent(r.Context(), bmcID, bodyBytes); err != nil {
+			log.Errorf("error handling hw event: %v", err)
+			writeHwEventError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
 		return
 	}
-	err = handleHwEvent(bodyBytes)
+	if err = queueHwEvent(bmcID, bodyBytes); err != nil {
+		log.Errorf("error queueing hw event: %v", err)
+		writeHwEventError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// statsHandler returns the event queue counters
+func statsHandler(w http.ResponseWriter, _ *http.Request) {
+	stats := map[string]interface{}{}
+	if eventQueue != nil {
+		stats["queue"] = eventQueue.Stats()
+	}
+	stats["contextMismatches"] = atomic.LoadUint64(&contextMismatches)
+	stats["clockSkews"] = atomic.LoadUint64(&clockSkews)
+	if msgParser != nil {
+		stats["messageParserServing"] = msgParser.Serving()
+		stats["messageParser"] = msgParser.Stats()
+	}
+	if msgCache != nil {
+		stats["messageCache"] = msgCache.Stats()
+	}
+	if eventOutbox != nil {
+		stats["outbox"] = eventOutbox.Stats()
+	}
+	writeJSON(w, stats)
+}
+
+// registriesHandler lists the message registries loaded in the proxy or by the message parser
+func registriesHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	if messageRegistry != nil {
+		writeJSON(w, map[string]interface{}{"source": "bundle", "registries": messageRegistry.List()})
+		return
+	}
+	if msgParser == nil {
+		writeRegistryError(w, parser.ErrUnavailable)
+		return
+	}
+	summaries, err := msgParser.ListRegistries()
 	if err != nil {
-		log.Errorf("error handling hw event: %v", err)
+		writeRegistryError(w, err)
+		return
 	}
+	writeJSON(w, map[string]interface{}{"source": "parser", "registries": summaries})
+}
+
+// resolveMessageHandler resolves the messageId query parameter with the arg parameters as MessageArgs,
+// bypassing the message cache
+func resolveMessageHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	m := redfish.EventRecord{MessageID: query.Get("messageId"), MessageArgs: query["arg"]}
+	if m.MessageID == "" {
+		webhook.WriteError(w, http.StatusBadRequest, webhook.PropertyMissing,
+			"The property messageId is a required property and must be included in the request.",
+			"Ensure that the property is in the request query and resubmit the request.")
+		return
+	}
+	resolved, err := parseMessage(m)
+	if err != nil {
+		writeRegistryError(w, err)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"MessageId":       m.MessageID,
+		"MessageArgs":     m.MessageArgs,
+		"Message":         resolved.Message,
+		"Severity":        resolved.Severity,
+		"Resolution":      resolved.Resolution,
+		"MessageRegistry": resolved.Metadata,
+	})
+}
+
+// unresolvedHandler lists the recent MessageIds that could not be resolved with their counts
+func unresolvedHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	writeJSON(w, map[string]interface{}{"unresolved": unresolvedMessages.List()})
+}
+
+// writeRegistryError maps the errors of the message registries to a Redfish error response
+func writeRegistryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, registry.ErrUnknownMessage), errors.Is(err, parser.ErrUnknownMessage):
+		webhook.WriteError(w, http.StatusNotFound, webhook.ResourceNotFound,
+			"The requested resource of type MessageId was not found.",
+			"Check the MessageId and the loaded registries and resubmit the request.")
+	case errors.Is(err, parser.ErrInvalidArgument):
+		webhook.WriteError(w, http.StatusBadRequest, webhook.PropertyValueFormatError,
+			err.Error(), "Correct the message arguments and resubmit the request.")
+	case errors.Is(err, parser.ErrUnavailable):
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", publisherRetryInterval))
+		webhook.WriteError(w, http.StatusServiceUnavailable, webhook.ServiceTemporarilyUnavailable,
+			"The message parser is temporarily unavailable.",
+			"Wait for the indicated retry duration and retry the operation.")
+	default:
+		log.Errorf("error reading message registries: %v", err)
+		webhook.WriteError(w, http.StatusInternalServerError, webhook.GeneralError,
+			"A general error has occurred.", "Retry the request.")
+	}
+}
+
+// allowGet rejects the requests other than GET and HEAD
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	w.WriteHeader(http.StatusMethodNotAllowed)
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b) //nolint:errcheck
+}
+
+// writeHwEventError maps errors returned by handleHwEvent to a Redfish error response
+func writeHwEventError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errInvalidEvent):
+		webhook.WriteError(w, http.StatusBadRequest, webhook.MalformedJSON,
+			"The request body submitted was malformed JSON and could not be parsed by the receiving service.",
+			"Ensure that the request body is valid JSON and resubmit the request.")
+	case errors.Is(err, errEventValidation):
+		writeValidationError(w, err)
+	case errors.Is(err, errUnexpectedContext):
+		webhook.WriteError(w, http.StatusBadRequest, webhook.PropertyValueNotInList,
+			"The value for the property Context is not in the list of acceptable values.",
+			"Update the event subscription of the BMC with the expected context.")
+	case errors.Is(err, errUnknownBMC):
+		webhook.WriteError(w, http.StatusNotFound, webhook.ResourceNotFound,
+			"The requested resource of type BMC was not found.",
+			"Provide a valid BMC ID and resubmit the request.")
+	case errors.Is(err, errPublisherUnavailable), errors.Is(err, errPublishFailed), errors.Is(err, errQueueFull):
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", publisherRetryInterval))
+		webhook.WriteError(w, http.StatusServiceUnavailable, webhook.ServiceTemporarilyUnavailable,
+			fmt.Sprintf("The service is temporarily unavailable.  Retry in %d seconds.", publisherRetryInterval),
+			"Wait for the indicated retry duration and retry the operation.")
+	default:
+		// the error may hold internal URLs and details, it is only logged by the caller
+		webhook.WriteError(w, http.StatusInternalServerError, webhook.InternalError,
+			"The request failed due to an internal service error.  The service is still operational.",
+			"Resubmit the request.  If the problem persists, consider resetting the service.")
+	}
+}
+
+// writeValidationError returns every schema violation of the event in @Message.ExtendedInfo
+func writeValidationError(w http.ResponseWriter, err error) {
+	var validationErr *validation.Error
+	if !errors.As(err, &validationErr) {
+		webhook.WriteError(w, http.StatusBadRequest, webhook.GeneralError, err.Error(), "None.")
+		return
+	}
+	var messages []webhook.Message
+	for _, v := range validationErr.Violations {
+		var m webhook.Message
+		if v.Type == validation.Missing {
+			m = webhook.NewMessage(webhook.PropertyMissing,
+				fmt.Sprintf("The property %s is a required property and must be included in the request.", v.Field),
+				"Ensure that the property is in the request body and has a valid value and resubmit the request if the operation failed.")
+			m.MessageArgs = []string{v.Field}
+		} else {
+			m = webhook.NewMessage(webhook.PropertyValueFormatError,
+				fmt.Sprintf("The value '%s' for the property %s is of a different format than the property can accept.", v.Value, v.Field),
+				"Correct the value for the property in the request body and resubmit the request if the operation failed.")
+			m.MessageArgs = []string{v.Value, v.Field}
+		}
+		messages = append(messages, m)
+	}
+	webhook.WriteErrorMessages(w, http.StatusBadRequest, messages...)
 }
 
 // handleHwEvent gets redfish HW events and converts it to cloud native event
 // and publishes to the event framework publisher
-func handleHwEvent(bodyBytes []byte) error {
+func handleHwEvent(ctx context.Context, bmcID string, bodyBytes []byte) error {
+	e, err := decodeHwEvent(bmcID, bodyBytes)
+	if err != nil {
+		return err
+	}
+	h, err := newHwEvent(bmcID, e)
+	if err != nil {
+		return err
+	}
+	return processHwEvent(ctx, h)
+}
+
+// queueHwEvent decodes the redfish HW event and adds it to the event queue,
+// the event is published asynchronously by the queue workers
+func queueHwEvent(bmcID string, bodyBytes []byte) error {
+	e, err := decodeHwEvent(bmcID, bodyBytes)
+	if err != nil {
+		return err
+	}
+	h, err := newHwEvent(bmcID, e)
+	if err != nil {
+		return err
+	}
+	return enqueueHwEvent(h)
+}
+
+// ingestHwEvent handles a redfish HW event read by the proxy from the SSE stream of the BMC,
+// the event goes through the same validation, queue and publishing as the webhook events
+func ingestHwEvent(ctx context.Context, bmcID string, e eventrecord.Event) error {
+	h, err := newIngestedHwEvent(bmcID, e)
+	if err != nil {
+		return err
+	}
+	if eventQueue == nil {
+		return processHwEvent(ctx, h)
+	}
+	return enqueueHwEvent(h)
+}
+
+// pollHwEvent handles the entries read by the proxy from a log service of the BMC. The event is
+// published without queue, as the cursor of the log service advances once pollHwEvent returns nil.
+func pollHwEvent(ctx context.Context, bmcID string, e eventrecord.Event) error {
+	h, err := newIngestedHwEvent(bmcID, e)
+	if err != nil {
+		return err
+	}
+	h.polled = true
+	return processHwEvent(ctx, h)
+}
+
+// newIngestedHwEvent validates a redfish HW event read by the proxy from the BMC
+func newIngestedHwEvent(bmcID string, e eventrecord.Event) (hwEvent, error) {
+	e.DeriveSeverity()
+	if err := validateHwEvent(bmcID, &e.Redfish); err != nil {
+		return hwEvent{}, err
+	}
+	return newHwEvent(bmcID, e)
+}
+
+func enqueueHwEvent(h hwEvent) error {
+	err := eventQueue.Enqueue(func(ctx context.Context) {
+		if processErr := processHwEvent(ctx, h); processErr != nil {
+			log.Errorf("error handling hw event from BMC %s: %v", h.publisher.ID, processErr)
+		}
+	})
+	if errors.Is(err, queue.ErrQueueFull) {
+		return errQueueFull
+	}
+	return err
+}
+
+// decodeHwEvent unmarshals the redfish HW event and validates it against the Redfish Event schema
+func decodeHwEvent(bmcID string, bodyBytes []byte) (eventrecord.Event, error) {
 	log.Tracef("webhook received event %s", bodyBytes)
-	e := createHwEvent()
-	redfishEvent := redfish.Event{}
-	if err := json.Unmarshal(bodyBytes, &redfishEvent); err != nil {
-		return fmt.Errorf("failed to unmarshal hw event: %v", err)
+	e, err := eventrecord.Decode(bodyBytes)
+	if err != nil {
+		return e, fmt.Errorf("%w: %v", errInvalidEvent, err)
 	}
+	e.DeriveSeverity()
+	return e, validateHwEvent(bmcID, &e.Redfish)
+}
 
-	for i, e := range redfishEvent.Events {
-		if e.Message == "" {
-			if parsed, err := parseMessage(e); err == nil {
-				redfishEvent.Events[i] = parsed
-			} else {
-				// ignore error
-				log.Debugf("error parsing message: %v", err)
-			}
+// validateHwEvent logs the Redfish Event schema violations of the event,
+// and repairs it or returns an error depending on the validation mode
+func validateHwEvent(bmcID string, redfishEvent *redfish.Event) error {
+	violations, err := validation.Validate(redfishEvent, validationMode)
+	for _, v := range violations {
+		log.Warnf("hw event %s from BMC %s: %s", redfishEvent.ID, bmcID, v)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", errEventValidation, err)
+	}
+	return nil
+}
+
+// hwEvent is a redfish event received from a BMC
+type hwEvent struct {
+	publisher    *bmcPublisher
+	redfishEvent redfish.Event
+	// records are the record properties not modeled by redfish.EventRecord
+	records []eventrecord.Fields
+	// unexpectedContext is set for events whose subscription context is not
+	// one of the contexts expected from the BMC
+	unexpectedContext bool
+	// receivedAt is the time the proxy received the event
+	receivedAt time.Time
+	// polled is set for events read from the log service of the BMC, their
+	// EventTimestamps are the creation times of old log entries
+	polled bool
+}
+
+// newHwEvent looks up the publisher of the BMC and verifies the subscription context of the event
+func newHwEvent(bmcID string, e eventrecord.Event) (hwEvent, error) {
+	p, err := getPublisher(bmcID)
+	if err != nil {
+		return hwEvent{}, err
+	}
+	redfishEvent := e.Redfish
+	h := hwEvent{publisher: p, redfishEvent: redfishEvent, records: e.Records, receivedAt: time.Now().UTC()}
+	if !p.VerifyContext(redfishEvent) {
+		atomic.AddUint64(&contextMismatches, 1)
+		if contextPolicy == contextPolicyReject {
+			log.Warnf("rejected hw event %s from BMC %s with unexpected context %q", redfishEvent.ID, bmcID, redfishEvent.Context)
+			return hwEvent{}, fmt.Errorf("%w %q", errUnexpectedContext, redfishEvent.Context)
 		}
+		log.Warnf("tagged hw event %s from BMC %s with unexpected context %q", redfishEvent.ID, bmcID, redfishEvent.Context)
+		h.unexpectedContext = true
 	}
+	return h, nil
+}
 
+func processHwEvent(ctx context.Context, h hwEvent) error {
+	p := h.publisher
+	redfishEvent := h.redfishEvent
+	parseMessages(redfishEvent.Events, h.records)
+	if !h.polled {
+		checkClockSkew(p, h.receivedAt, redfishEvent.Events)
+	}
+	records := make([]eventrecord.Fields, len(redfishEvent.Events))
+	copy(records, h.records)
+	for i, record := range redfishEvent.Events {
+		records[i].RecordID = recordEventID(p, redfishEvent, record, h.receivedAt)
+	}
+	if !splitEvents || len(redfishEvent.Events) == 0 {
+		e := createHwEvent(p, eventTime(h, redfishEvent.Events))
+		return publishRedfishEvent(ctx, h, e, redfishEvent, records)
+	}
+	// every record is published even if some fail, the BMC sends the whole event again
+	// on failure and the records already published are recognized by their RecordId
+	var published, failed []string
+	var errs []error
+	for i, record := range redfishEvent.Events {
+		// the envelope of the Redfish event is copied with a single record
+		single := redfishEvent
+		single.Events = []redfish.EventRecord{record}
+		e := createHwEvent(p, eventTime(h, single.Events))
+		if err := publishRedfishEvent(ctx, h, e, single, records[i:i+1]); err != nil {
+			failed = append(failed, record.MemberID)
+			errs = append(errs, err)
+			continue
+		}
+		published = append(published, record.MemberID)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("records %v of hw event %s not published, records %v published: %w",
+			failed, redfishEvent.ID, published, errors.Join(errs...))
+	}
+	return nil
+}
+
+// publishRedfishEvent sets the Redfish event as the data of the cloud event and publishes it
+func publishRedfishEvent(ctx context.Context, h hwEvent, e event.Event, redfishEvent redfish.Event, records []eventrecord.Fields) error {
+	p := h.publisher
+	var err error
+	if redfishEvent.Events, err = eventrecord.SetOem(redfishEvent.Events, records); err != nil {
+		return fmt.Errorf("error adding record fields to event %v", err)
+	}
+	// the ID and the receive time are kept in the Redfish event, the sidecar replaces the ID
+	// and drops the extension attributes of the cloud event
+	if redfishEvent, err = eventrecord.SetEventOem(redfishEvent, eventrecord.EventFields{
+		UniqueID:     hwEventID(p, redfishEvent, records),
+		ReceivedTime: h.receivedAt.Format(time.RFC3339Nano),
+	}); err != nil {
+		return fmt.Errorf("error adding fields to event %v", err)
+	}
 	data := v1event.CloudNativeData()
 	value := event.DataValue{
-		Resource:  string(redfish.Systems),
+		Resource:  p.Resource(),
 		DataType:  event.NOTIFICATION,
 		ValueType: event.REDFISH_EVENT,
 		Value:     redfishEvent,
 	}
 	data.SetVersion(hwEventVersion) //nolint:errcheck
 	data.AppendValues(value)        //nolint:errcheck
+	if h.unexpectedContext {
+		data.AppendValues(event.DataValue{ //nolint:errcheck
+			Resource:  p.Resource(),
+			DataType:  event.NOTIFICATION,
+			ValueType: event.ENUMERATION,
+			Value:     unexpectedContextTag,
+		})
+	}
 	e.SetData(data)
-	if err := publishHwEvent(e); err != nil {
-		return fmt.Errorf("failed to publish hw event: %v", err)
+	if err = publishHwEvent(ctx, p.ID, e); err != nil {
+		return fmt.Errorf("%w: %v", errPublishFailed, err)
 	}
 	return nil
 }
 
-func parseMessage(m redfish.EventRecord) (redfish.EventRecord, error) {
-	addr := fmt.Sprintf("localhost:%d", msgParserPort)
-	ctx, cancel := context.WithTimeout(context.Background(), msgParserTimeout)
-	defer cancel()
-	conn, err := grpc.DialContext(ctx, addr, grpc.WithBlock(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+// hwEventID returns the UniqueId of a published Redfish event, a random UUID, or with deterministicIDs
+// a name based UUID of the resource address of the BMC, the Id of the event and the RecordIds of its records
+func hwEventID(p *bmcPublisher, e redfish.Event, records []eventrecord.Fields) string {
+	if !deterministicIDs {
+		return uuid.New().String()
+	}
+	recordIDs := make([]string, 0, len(records))
+	for _, r := range records {
+		recordIDs = append(recordIDs, r.RecordID)
+	}
+	name := fmt.Sprintf("%s/events/%s/records/%s", p.ResourceAddress, e.ID, strings.Join(recordIDs, ","))
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(name)).String()
+}
 
-	if err != nil {
-		return redfish.EventRecord{}, err
+// recordEventID returns the RecordId of a record, a name based UUID of the resource address of the BMC
+// and the EventId of the record, or the Id of the event and the MemberId of the record for records without
+// EventId. The same record gets the same ID when the BMC sends it again. The receive time is added for
+// events without Id, whose records can't be told apart from the records of other events.
+func recordEventID(p *bmcPublisher, e redfish.Event, r redfish.EventRecord, receivedAt time.Time) string {
+	var name string
+	switch {
+	case r.EventID != "":
+		name = fmt.Sprintf("%s/records/%s", p.ResourceAddress, r.EventID)
+	case e.ID != "":
+		name = fmt.Sprintf("%s/events/%s/records/%s", p.ResourceAddress, e.ID, r.MemberID)
+	default:
+		name = fmt.Sprintf("%s/events/received/%d/records/%s", p.ResourceAddress, receivedAt.UnixNano(), r.MemberID)
 	}
-	defer conn.Close()
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(name)).String()
+}
 
-	client := pb.NewMessageParserClient(conn)
-	req := &pb.ParserRequest{
-		MessageId:   m.MessageID,
-		MessageArgs: m.MessageArgs,
+// newMessageCache creates the cache of parsed messages, unknown messages are cached for a
+// shorter time so that the registries loaded later are used
+func newMessageCache() *msgcache.Cache {
+	size := util.GetIntEnv("HW_EVENT_MESSAGE_CACHE_SIZE", 1000)
+	if size <= 0 {
+		return nil
 	}
+	return msgcache.New(size,
+		time.Duration(util.GetIntEnv("HW_EVENT_MESSAGE_CACHE_TTL", 3600))*time.Second,
+		time.Duration(util.GetIntEnv("HW_EVENT_MESSAGE_CACHE_NEGATIVE_TTL", 60))*time.Second)
+}
 
-	resp, err := client.Parse(context.Background(), req)
-	if resp.Message == "unknown" {
-		err = fmt.Errorf("unable to find message in Redfish Registries")
+// parseMessages parses the message of the records without one and adds the registry message
+// used to their fields. The records of an event are sent to the message parser sidecar in one call.
+// Parsed and unknown messages are cached.
+func parseMessages(records []redfish.EventRecord, fields []eventrecord.Fields) {
+	var indexes []int
+	var unparsed []redfish.EventRecord
+	if enrichmentPolicy == enrichmentNever {
+		return
+	}
+	for i, m := range records {
+		if m.Message != "" && enrichmentPolicy != enrichmentFill {
+			continue
+		}
+		if msgCache != nil {
+			if entry, ok := msgCache.Get(m.MessageID, m.MessageArgs); ok {
+				if entry.Known {
+					setMessage(records, fields, i, entry.Resolved)
+				} else {
+					unresolvedMessages.Add(m.MessageID, nil)
+				}
+				continue
+			}
+		}
+		indexes = append(indexes, i)
+		unparsed = append(unparsed, m)
+	}
+	if len(unparsed) == 0 {
+		return
 	}
+	if messageRegistry != nil || len(unparsed) == 1 {
+		for _, i := range indexes {
+			resolved, err := parseMessage(records[i])
+			cacheMessage(records[i], resolved, err)
+			if err != nil {
+				countUnresolved(records[i], err)
+				// ignore error
+				log.Debugf("error parsing message: %v", err)
+				continue
+			}
+			setMessage(records, fields, i, resolved)
+		}
+		return
+	}
+	if msgParser == nil {
+		return
+	}
+	results, errs, err := msgParser.ParseBatch(unparsed)
 	if err != nil {
-		return redfish.EventRecord{}, err
+		log.Debugf("error parsing messages: %v", err)
+		return
+	}
+	for j, i := range indexes {
+		cacheMessage(records[i], results[j], errs[j])
+		if errs[j] != nil {
+			countUnresolved(records[i], errs[j])
+			log.Debugf("error parsing message: %v", errs[j])
+			continue
+		}
+		setMessage(records, fields, i, results[j])
+	}
+}
+
+// setMessage sets the message of a record and the registry message used in its fields,
+// parsers not returning the registry message leave the fields unchanged. The message sent by the BMC
+// is kept, only its missing Resolution is filled in and its Severity normalized.
+func setMessage(records []redfish.EventRecord, fields []eventrecord.Fields, i int, resolved registry.Resolved) {
+	m := &records[i]
+	if m.Message == "" {
+		m.Message = resolved.Message
+		m.Severity = resolved.Severity
+		m.Resolution = resolved.Resolution
+	} else {
+		if m.Resolution == "" {
+			m.Resolution = resolved.Resolution
+		}
+		m.Severity = normalizeSeverity(m.Severity, resolved.Severity)
+	}
+	if i < len(fields) && resolved.MessageKey != "" {
+		metadata := resolved.Metadata
+		fields[i].MessageRegistry = &metadata
+	}
+}
+
+// normalizeSeverity returns the Health value of the severity sent by the BMC, e.g. OK for ok,
+// or the severity of the registry message when it is not a Health value
+func normalizeSeverity(severity, registrySeverity string) string {
+	for _, health := range []string{"OK", "Warning", "Critical"} {
+		if strings.EqualFold(severity, health) {
+			return health
+		}
+	}
+	if registrySeverity == "" {
+		return severity
+	}
+	return registrySeverity
+}
+
+// cacheMessage caches the result of parsing a record, unknown messages and invalid arguments
+// are cached as such while other errors such as an unavailable parser are not cached
+func cacheMessage(m redfish.EventRecord, resolved registry.Resolved, err error) {
+	if msgCache == nil {
+		return
+	}
+	switch {
+	case err == nil:
+		msgCache.Add(m.MessageID, m.MessageArgs, msgcache.Entry{Known: true, Resolved: resolved})
+	case isUnresolved(err):
+		msgCache.Add(m.MessageID, m.MessageArgs, msgcache.Entry{})
 	}
-	m.Message = resp.Message
-	m.Severity = resp.Severity
-	m.Resolution = resp.Resolution
-	return m, nil
 }
 
-func createHwEvent() event.Event {
+// countUnresolved counts the MessageIds not found in the registries or with invalid arguments
+func countUnresolved(m redfish.EventRecord, err error) {
+	if isUnresolved(err) {
+		unresolvedMessages.Add(m.MessageID, err)
+	}
+}
+
+// isUnresolved returns true for the errors of MessageIds not found in the registries or with
+// invalid arguments, which don't change until the registries do
+func isUnresolved(err error) bool {
+	return errors.Is(err, registry.ErrUnknownMessage) || errors.Is(err, parser.ErrUnknownMessage) ||
+		errors.Is(err, parser.ErrInvalidArgument)
+}
+
+// parseMessage resolves the message, severity and resolution of a record from its MessageId,
+// in-process when the message registries are loaded or else by the message parser sidecar
+func parseMessage(m redfish.EventRecord) (registry.Resolved, error) {
+	if messageRegistry == nil {
+		return parseMessageRemote(m)
+	}
+	return messageRegistry.Resolve(m.MessageID, m.MessageArgs)
+}
+
+// parseMessageRemote parses the message with the message parser sidecar
+func parseMessageRemote(m redfish.EventRecord) (registry.Resolved, error) {
+	if msgParser == nil {
+		return registry.Resolved{}, parser.ErrUnavailable
+	}
+	return msgParser.Parse(m)
+}
+
+// splitList splits a comma separated list, ignoring empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// eventTime returns the earliest EventTimestamp of the records, or the receive time of the event
+// when none of the records has a valid EventTimestamp
+func eventTime(h hwEvent, records []redfish.EventRecord) time.Time {
+	if t, ok := eventrecord.EarliestTimestamp(records); ok {
+		return t
+	}
+	return h.receivedAt
+}
+
+// checkClockSkew compares the latest EventTimestamp of the records with the receive time
+// and logs when the clock of the BMC becomes skewed and when it is in sync again
+func checkClockSkew(p *bmcPublisher, receivedAt time.Time, records []redfish.EventRecord) {
+	if clockSkewThreshold <= 0 {
+		return
+	}
+	var latest time.Time
+	for _, r := range records {
+		if t, ok := eventrecord.ParseTimestamp(r.EventTimestamp); ok && t.After(latest) {
+			latest = t
+		}
+	}
+	if latest.IsZero() {
+		return
+	}
+	skew := latest.Sub(receivedAt)
+	if skew.Abs() > clockSkewThreshold {
+		atomic.AddUint64(&clockSkews, 1)
+		if !p.clockSkewed.Swap(true) {
+			log.Warnf("clock of BMC %s is skewed by %s, EventTimestamp %s received at %s",
+				p.ID, skew.Round(time.Second), latest.Format(time.RFC3339), receivedAt.Format(time.RFC3339))
+		}
+		return
+	}
+	if p.clockSkewed.Swap(false) {
+		log.Infof("clock of BMC %s is in sync again", p.ID)
+	}
+}
+
+func createHwEvent(p *bmcPublisher, t time.Time) event.Event {
 	e := v1event.CloudNativeEvent()
-	e.ID = pub.ID
+	// the sidecar looks up the publisher by the event ID and gives the published event a new ID
+	e.ID = p.pub.ID
 	e.Type = string(redfish.Alert)
-	e.Source = resourceAddress
-	e.SetTime(types.Timestamp{Time: time.Now().UTC()}.Time)
+	e.Source = p.ResourceAddress
+	e.SetTime(t)
 	e.SetDataContentType(event.ApplicationJSON)
 	return e
 }
 
-func publishHwEvent(e event.Event) error {
-	url := fmt.Sprintf("%s%s", baseURL, "create/event")
-	rc := restclient.New()
+// publishHwEvent persists the event in the outbox of the BMC, which publishes
+// it in the background, or publishes it at once without outbox
+func publishHwEvent(ctx context.Context, bmcID string, e event.Event) error {
 	b, err := json.Marshal(e)
 	if err != nil {
 		return fmt.Errorf("error marshalling event %v", err)
 	}
-	if status := rc.Post(types.ParseURI(url), b); status == http.StatusBadRequest {
-		return fmt.Errorf("post returned status %d", status)
+	if eventOutbox != nil {
+		return eventOutbox.Add(bmcID, b)
+	}
+	if err = postHwEvent(ctx, b); err != nil {
+		return err
 	}
 	log.Debugf("published hw event %s", e)
 	return nil
 }
+
+// postHwEvent posts a marshaled event to the sidecar, the event is published only on a 2xx status
+func postHwEvent(ctx context.Context, b []byte) error {
+	url := fmt.Sprintf("%s%s", baseURL, "create/event")
+	return restClient.Post(ctx, types.ParseURI(url), b)
+}
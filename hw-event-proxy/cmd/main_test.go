@@ -4,13 +4,59 @@
 package main
 
 import (
-	"github.com/stretchr/testify/assert"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/bmc"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/eventrecord"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/logservice"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/outbox"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/queue"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/registry"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/restclient"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/validation"
+	"github.com/redhat-cne/sdk-go/pkg/event"
+	"github.com/redhat-cne/sdk-go/pkg/event/redfish"
+	"github.com/redhat-cne/sdk-go/pkg/pubsub"
+	"github.com/redhat-cne/sdk-go/pkg/types"
+	"github.com/stretchr/testify/assert"
 )
 
+const testEvent = `{
+  "@odata.context": "/redfish/v1/$metadata#Event.Event",
+  "@odata.type": "#Event.v1_3_0.Event",
+  "Context": "any string is valid",
+  "Events": [
+    {
+      "Context": "any string is valid",
+      "EventId": "2162",
+      "EventTimestamp": "2021-07-13T15:07:59+0300",
+      "EventType": "Alert",
+      "MemberId": "615703",
+      "Message": "The system board Inlet temperature is less than the lower warning threshold.",
+      "MessageArgs": ["Inlet"],
+      "MessageId": "TMP0100",
+      "Severity": "Warning"
+    }
+  ],
+  "Id": "5e004f5a-e3d1-11eb-ae9c-3448edf18a38",
+  "Name": "Event Array"
+}`
+
 func TestHandleHwEventInvalidChars(t *testing.T) {
 	b := []byte("€\u263a")
-	err := handleHwEvent(b)
+	err := handleHwEvent(context.Background(), bmc.DefaultID, b)
 	expectedErr := "failed to unmarshal hw event"
 	assert.Containsf(t, err.Error(), expectedErr,
 		"expected error contains '%v', got %v", expectedErr, err.Error())
@@ -19,8 +65,513 @@ func TestHandleHwEventInvalidChars(t *testing.T) {
 // verify handleHwEvent can handle large payload without crash
 func TestHandleHwEvent64K(t *testing.T) {
 	b := make([]byte, 65536)
-	err := handleHwEvent(b)
+	err := handleHwEvent(context.Background(), bmc.DefaultID, b)
 	expectedErr := "failed to unmarshal hw event"
 	assert.Containsf(t, err.Error(), expectedErr,
 		"expected error contains '%v', got %v", expectedErr, err.Error())
 }
+
+func TestWebhookMalformedEvent(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader("€☺"))
+	w := httptest.NewRecorder()
+	webhookHandler(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"@Message.ExtendedInfo"`)
+	assert.Contains(t, w.Body.String(), "Base.1.8.MalformedJSON")
+}
+
+func TestWebhookEventTooLarge(t *testing.T) {
+	b := make([]byte, maxEventSize+1)
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(b))
+	w := httptest.NewRecorder()
+	webhookHandler(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestWebhookPublisherUnavailable(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(testEvent))
+	w := httptest.NewRecorder()
+	webhookHandler(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "Base.1.8.ServiceTemporarilyUnavailable")
+}
+
+func TestWriteHwEventInternalError(t *testing.T) {
+	w := httptest.NewRecorder()
+	writeHwEventError(w, errors.New("post http://localhost:9043/api/ocloudNotifications/v1/create/event: connection refused"))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Base.1.8.InternalError")
+	assert.NotContains(t, w.Body.String(), "localhost")
+}
+
+func TestPollWithQueue(t *testing.T) {
+	entries := []string{`{"Id": "1", "Created": "2021-07-06T01:17:12-0400", "MessageId": "TMP0120", "Severity": "Warning"}`}
+	bmcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"Members": [%s]}`, strings.Join(entries, ","))
+	}))
+	defer bmcServer.Close()
+	var posted, failing int32
+	sidecar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&posted, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer sidecar.Close()
+
+	stopCh := make(chan struct{})
+	prevURL, prevClient, prevQueue := baseURL, restClient, eventQueue
+	publishers[bmc.DefaultID].pub = pubsub.PubSub{ID: "test-publisher"}
+	baseURL, restClient = types.ParseURI(sidecar.URL+"/"), restclient.New(time.Second)
+	eventQueue = queue.New(10, 1, queue.DropNewest)
+	eventQueue.Start(stopCh)
+	defer func() {
+		close(stopCh)
+		publishers[bmc.DefaultID].pub = pubsub.PubSub{}
+		baseURL, restClient, eventQueue = prevURL, prevClient, prevQueue
+	}()
+
+	b := bmc.BMC{ID: bmc.DefaultID, Redfish: &bmc.Connection{Address: bmcServer.URL}}
+	p := logservice.NewPoller(b.ID, b.Redfish, []string{"/redfish/v1/Systems/1/LogServices/Sel/Entries"}, 0, t.TempDir(), pollHandler(b))
+	ctx := context.Background()
+	// the first poll only sets the cursor
+	p.Poll(ctx)
+
+	// the entries are published without queue, the cursor doesn't move when publishing fails
+	entries = append(entries, `{"Id": "2", "Created": "2021-07-06T01:18:12-0400", "MessageId": "TMP0100", "Severity": "Warning"}`)
+	atomic.StoreInt32(&failing, 1)
+	p.Poll(ctx)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&posted))
+	atomic.StoreInt32(&failing, 0)
+	p.Poll(ctx)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&posted))
+	p.Poll(ctx)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&posted))
+	assert.Equal(t, uint64(0), eventQueue.Stats().Enqueued)
+}
+
+func TestWebhookQueueFull(t *testing.T) {
+	publishers[bmc.DefaultID].pub = pubsub.PubSub{ID: "test-publisher"}
+	eventQueue = queue.New(1, 1, queue.Reject)
+	defer func() {
+		publishers[bmc.DefaultID].pub = pubsub.PubSub{}
+		eventQueue = nil
+	}()
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(testEvent))
+	w := httptest.NewRecorder()
+	webhookHandler(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(testEvent))
+	w = httptest.NewRecorder()
+	webhookHandler(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, uint64(1), eventQueue.Stats().Rejected)
+}
+
+func TestWebhookBMCRoutes(t *testing.T) {
+	publishers["chassis"] = &bmcPublisher{BMC: bmc.BMC{ID: "chassis", ResourceAddress: "/cluster/node/mock/redfish/v1/Chassis"}}
+	defer delete(publishers, "chassis")
+
+	// publisher for the chassis BMC is not created yet
+	req := httptest.NewRequest(http.MethodPost, "/webhook/chassis", strings.NewReader(testEvent))
+	w := httptest.NewRecorder()
+	webhookHandler(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/webhook/unknown", strings.NewReader(testEvent))
+	w = httptest.NewRecorder()
+	webhookHandler(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Base.1.8.ResourceNotFound")
+
+	assert.Equal(t, "/redfish/v1/Chassis", publishers["chassis"].Resource())
+}
+
+func TestWebhookStrictValidation(t *testing.T) {
+	validationMode = validation.Strict
+	defer func() { validationMode = validation.Warn }()
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"@odata.type": "#Event.v1_3_0.Event", "Id": "1", "Name": "n", "Events": [{"MemberId": "0"}]}`))
+	w := httptest.NewRecorder()
+	webhookHandler(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Base.1.8.PropertyMissing")
+	assert.Contains(t, w.Body.String(), "Events[0].MessageId")
+}
+
+func TestWebhookUnexpectedContext(t *testing.T) {
+	publishers["ctx"] = &bmcPublisher{
+		BMC: bmc.BMC{ID: "ctx", Contexts: []string{"node-1"}},
+		pub: pubsub.PubSub{ID: "test-publisher"},
+	}
+	defer delete(publishers, "ctx")
+
+	// testEvent has context "any string is valid"
+	req := httptest.NewRequest(http.MethodPost, "/webhook/ctx", strings.NewReader(testEvent))
+	w := httptest.NewRecorder()
+	webhookHandler(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Base.1.8.PropertyValueNotInList")
+
+	contextPolicy = contextPolicyTag
+	defer func() { contextPolicy = contextPolicyReject }()
+	h, err := newHwEvent("ctx", eventrecord.Event{Redfish: redfish.Event{Context: "node-2"}})
+	assert.NoError(t, err)
+	assert.True(t, h.unexpectedContext)
+	h, err = newHwEvent("ctx", eventrecord.Event{Redfish: redfish.Event{Context: "node-1"}})
+	assert.NoError(t, err)
+	assert.False(t, h.unexpectedContext)
+}
+
+func TestParseMessageInProcess(t *testing.T) {
+	messageRegistry = registry.NewResolver()
+	defer func() { messageRegistry = nil }()
+	assert.NoError(t, messageRegistry.LoadFile("../registry/testdata/IDRAC.2.8.json"))
+
+	m, err := parseMessage(redfish.EventRecord{MessageID: "TMP0100", MessageArgs: []string{"Inlet"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "The system board Inlet temperature is less than the lower warning threshold.", m.Message)
+	assert.Equal(t, "Warning", m.Severity)
+	assert.NotEmpty(t, m.Resolution)
+
+	_, err = parseMessage(redfish.EventRecord{MessageID: "IDRAC.2.8.FAN0001"})
+	assert.ErrorIs(t, err, registry.ErrUnknownMessage)
+
+	records := []redfish.EventRecord{
+		{MessageID: "IDRAC.2.8.TMP0120", MessageArgs: []string{"Inlet"}},
+		{MessageID: "IDRAC.2.8.TMP0100", MessageArgs: []string{"Inlet"}, Message: "Inlet temperature is low"},
+	}
+	fields := make([]eventrecord.Fields, len(records))
+	parseMessages(records, fields)
+	assert.Equal(t, "The system board Inlet temperature is within range.", records[0].Message)
+	assert.Equal(t, "2.8.0", fields[0].MessageRegistry.RegistryVersion)
+	assert.Equal(t, []string{"TMP0100"}, fields[0].MessageRegistry.ClearingLogic.ClearsMessage)
+	// records with a message are not parsed
+	assert.Equal(t, "Inlet temperature is low", records[1].Message)
+	assert.Nil(t, fields[1].MessageRegistry)
+}
+
+// sidecarEvent decodes a posted event as the sidecar does, the unknown properties are dropped
+func sidecarEvent(t *testing.T, b []byte) (event.Event, redfish.Event) {
+	e := event.Event{}
+	assert.NoError(t, json.Unmarshal(b, &e))
+	if !assert.NotNil(t, e.Data) || !assert.NotEmpty(t, e.Data.Values) {
+		return e, redfish.Event{}
+	}
+	redfishEvent, ok := e.Data.Values[0].Value.(redfish.Event)
+	assert.True(t, ok)
+	return e, redfishEvent
+}
+
+func TestPublishMessageRegistry(t *testing.T) {
+	messageRegistry = registry.NewResolver()
+	assert.NoError(t, messageRegistry.LoadFile("../registry/testdata/IDRAC.2.8.json"))
+	publishers[bmc.DefaultID].pub = pubsub.PubSub{ID: "test-publisher"}
+	published := make(chan []byte, 1)
+	o, err := outbox.New(t.TempDir(), 10, time.Hour, func(_ context.Context, b []byte) error {
+		published <- b
+		return nil
+	})
+	assert.NoError(t, err)
+	stopCh := make(chan struct{})
+	assert.NoError(t, o.Start(stopCh))
+	eventOutbox = o
+	defer func() {
+		close(stopCh)
+		publishers[bmc.DefaultID].pub = pubsub.PubSub{}
+		eventOutbox, messageRegistry = nil, nil
+	}()
+
+	assert.NoError(t, handleHwEvent(context.Background(), bmc.DefaultID, []byte(`{"@odata.type": "#Event.v1_3_0.Event", "Id": "1", "Name": "Event Array",
+		"Events": [{"EventType": "Alert", "MemberId": "0", "MessageId": "IDRAC.2.8.TMP0120", "MessageArgs": ["Inlet"]}]}`)))
+	select {
+	case b := <-published:
+		_, redfishEvent := sidecarEvent(t, b)
+		record := redfishEvent.Events[0]
+		assert.Equal(t, "The system board Inlet temperature is within range.", record.Message)
+		var oem map[string]struct {
+			MessageRegistry registry.Metadata
+		}
+		assert.NoError(t, json.Unmarshal(record.Oem, &oem))
+		m := oem[eventrecord.OemKey].MessageRegistry
+		assert.Equal(t, "TMP0120", m.MessageKey)
+		assert.Equal(t, "2.8.0", m.RegistryVersion)
+		assert.Equal(t, []string{"TMP0100"}, m.ClearingLogic.ClearsMessage)
+	case <-time.After(time.Second):
+		assert.Fail(t, "event not published")
+	}
+}
+
+func TestMergeCachedRegistries(t *testing.T) {
+	b, err := os.ReadFile("../registry/testdata/IDRAC.2.8.json")
+	assert.NoError(t, err)
+	r, err := registry.Parse(b)
+	assert.NoError(t, err)
+	cache := registry.NewCache(t.TempDir(), "bmc-1")
+	assert.NoError(t, cache.Save("uuid-1", []*registry.Registry{r}))
+
+	messageRegistry = registry.NewResolver()
+	defer func() { messageRegistry = nil }()
+	// the cache was downloaded from another BMC
+	mergeCachedRegistries("bmc-1", "uuid-2", cache)
+	assert.Empty(t, messageRegistry.List())
+	mergeCachedRegistries("bmc-1", "uuid-1", cache)
+	assert.Len(t, messageRegistry.List(), 1)
+}
+
+func TestRegistryAPI(t *testing.T) {
+	messageRegistry = registry.NewResolver()
+	defer func() { messageRegistry = nil }()
+	assert.NoError(t, messageRegistry.LoadFile("../registry/testdata/IDRAC.2.8.json"))
+
+	w := httptest.NewRecorder()
+	registriesHandler(w, httptest.NewRequest(http.MethodGet, "/registries", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"RegistryVersion":"2.8.0"`)
+
+	w = httptest.NewRecorder()
+	resolveMessageHandler(w, httptest.NewRequest(http.MethodGet, "/registries/resolve?messageId=IDRAC.2.8.TMP0100&arg=Inlet", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "The system board Inlet temperature is less than the lower warning threshold.")
+
+	w = httptest.NewRecorder()
+	resolveMessageHandler(w, httptest.NewRequest(http.MethodGet, "/registries/resolve?messageId=IDRAC.2.8.FAN0001", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = httptest.NewRecorder()
+	resolveMessageHandler(w, httptest.NewRequest(http.MethodGet, "/registries/resolve", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = httptest.NewRecorder()
+	resolveMessageHandler(w, httptest.NewRequest(http.MethodPost, "/registries/resolve", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	parseMessages([]redfish.EventRecord{{MessageID: "IDRAC.2.8.FAN0001"}}, nil)
+	w = httptest.NewRecorder()
+	unresolvedHandler(w, httptest.NewRequest(http.MethodGet, "/registries/unresolved", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"MessageId":"IDRAC.2.8.FAN0001","Count":1`)
+}
+
+func TestEnrichmentPolicy(t *testing.T) {
+	messageRegistry = registry.NewResolver()
+	defer func() { messageRegistry, enrichmentPolicy = nil, enrichmentEmpty }()
+	assert.NoError(t, messageRegistry.LoadFile("../registry/testdata/IDRAC.2.8.json"))
+
+	newRecords := func() []redfish.EventRecord {
+		return []redfish.EventRecord{
+			{MessageID: "TMP0100", MessageArgs: []string{"Inlet"}, Message: "Inlet temperature is low", Severity: "warning"},
+			{MessageID: "TMP0100", MessageArgs: []string{"Inlet"}, Message: "Inlet temperature is low", Severity: "Informational"},
+			{MessageID: "TMP0100", MessageArgs: []string{"Inlet"}},
+		}
+	}
+
+	enrichmentPolicy = enrichmentNever
+	records := newRecords()
+	parseMessages(records, nil)
+	assert.Equal(t, newRecords(), records)
+
+	enrichmentPolicy = enrichmentEmpty
+	records = newRecords()
+	parseMessages(records, nil)
+	assert.Equal(t, newRecords()[:2], records[:2])
+	assert.Equal(t, "The system board Inlet temperature is less than the lower warning threshold.", records[2].Message)
+
+	enrichmentPolicy = enrichmentFill
+	records = newRecords()
+	fields := make([]eventrecord.Fields, len(records))
+	parseMessages(records, fields)
+	// the message of the BMC is kept
+	assert.Equal(t, "Inlet temperature is low", records[0].Message)
+	assert.Equal(t, "Warning", records[0].Severity)
+	assert.NotEmpty(t, records[0].Resolution)
+	assert.Equal(t, "TMP0100", fields[0].MessageRegistry.MessageKey)
+	assert.Equal(t, "Warning", records[1].Severity)
+	assert.Equal(t, "The system board Inlet temperature is less than the lower warning threshold.", records[2].Message)
+}
+
+func TestWebhookOutbox(t *testing.T) {
+	publishers[bmc.DefaultID].pub = pubsub.PubSub{ID: "test-publisher"}
+	published := make(chan []byte, 1)
+	o, err := outbox.New(t.TempDir(), 10, time.Hour, func(_ context.Context, b []byte) error {
+		published <- b
+		return nil
+	})
+	assert.NoError(t, err)
+	stopCh := make(chan struct{})
+	assert.NoError(t, o.Start(stopCh))
+	eventOutbox = o
+	defer func() {
+		close(stopCh)
+		publishers[bmc.DefaultID].pub = pubsub.PubSub{}
+		eventOutbox = nil
+	}()
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(testEvent))
+	w := httptest.NewRecorder()
+	webhookHandler(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	select {
+	case b := <-published:
+		assert.Contains(t, string(b), "5e004f5a-e3d1-11eb-ae9c-3448edf18a38")
+		// the sidecar looks up the publisher by the event ID, it must survive its decoding
+		e, redfishEvent := sidecarEvent(t, b)
+		assert.Equal(t, "test-publisher", e.ID)
+		// the event time is the EventTimestamp of the record, the receive time is in the Oem of the Redfish event
+		assert.Contains(t, string(b), `"time":"2021-07-13T12:07:59Z"`)
+		var oem map[string]eventrecord.EventFields
+		assert.NoError(t, json.Unmarshal(redfishEvent.Oem, &oem))
+		_, ok := eventrecord.ParseTimestamp(oem[eventrecord.OemKey].ReceivedTime)
+		assert.True(t, ok)
+		// the unique ID of the event and the IDs of its records are kept in the Oem
+		_, err = uuid.Parse(oem[eventrecord.OemKey].UniqueID)
+		assert.NoError(t, err)
+		var recordOem map[string]eventrecord.Fields
+		assert.NoError(t, json.Unmarshal(redfishEvent.Events[0].Oem, &recordOem))
+		assert.Equal(t, recordEventID(publishers[bmc.DefaultID], redfishEvent, redfishEvent.Events[0], time.Time{}),
+			recordOem[eventrecord.OemKey].RecordID)
+	case <-time.After(time.Second):
+		assert.Fail(t, "event not published")
+	}
+}
+
+const testEventTwoRecords = `{
+  "@odata.type": "#Event.v1_3_0.Event",
+  "Context": "any string is valid",
+  "Events": [
+    {"EventId": "2162", "EventType": "Alert", "MemberId": "0", "MessageId": "TMP0100", "MessageArgs": ["Inlet"], "Severity": "Warning",
+     "Message": "The system board Inlet temperature is less than the lower warning threshold.", "OriginOfCondition": {"@odata.id": "/redfish/v1/Systems/System.Embedded.1"}},
+    {"EventType": "Alert", "MemberId": "1", "MessageId": "TMP0100", "MessageArgs": ["Exhaust"], "Severity": "Warning",
+     "Message": "The system board Exhaust temperature is less than the lower warning threshold.", "OriginOfCondition": {"@odata.id": "/redfish/v1/Systems/System.Embedded.1"}}
+  ],
+  "Id": "5e004f5a-e3d1-11eb-ae9c-3448edf18a38",
+  "Name": "Event Array"
+}`
+
+func TestSplitEvents(t *testing.T) {
+	publishers[bmc.DefaultID].pub = pubsub.PubSub{ID: "test-publisher"}
+	published := make(chan []byte, 4)
+	o, err := outbox.New(t.TempDir(), 10, time.Hour, func(_ context.Context, b []byte) error {
+		published <- b
+		return nil
+	})
+	assert.NoError(t, err)
+	stopCh := make(chan struct{})
+	assert.NoError(t, o.Start(stopCh))
+	eventOutbox, splitEvents = o, true
+	defer func() {
+		close(stopCh)
+		publishers[bmc.DefaultID].pub = pubsub.PubSub{}
+		eventOutbox, splitEvents = nil, false
+	}()
+
+	recordIDs := func() []string {
+		assert.NoError(t, handleHwEvent(context.Background(), bmc.DefaultID, []byte(testEventTwoRecords)))
+		var ids []string
+		for i := 0; i < 2; i++ {
+			select {
+			case b := <-published:
+				e, redfishEvent := sidecarEvent(t, b)
+				assert.Equal(t, "test-publisher", e.ID)
+				assert.Equal(t, "5e004f5a-e3d1-11eb-ae9c-3448edf18a38", redfishEvent.ID)
+				assert.Equal(t, "any string is valid", redfishEvent.Context)
+				assert.Len(t, redfishEvent.Events, 1)
+				assert.Equal(t, fmt.Sprintf("%d", i), redfishEvent.Events[0].MemberID)
+				var oem map[string]eventrecord.Fields
+				assert.NoError(t, json.Unmarshal(redfishEvent.Events[0].Oem, &oem))
+				ids = append(ids, oem[eventrecord.OemKey].RecordID)
+			case <-time.After(time.Second):
+				assert.FailNow(t, "event not published")
+			}
+		}
+		return ids
+	}
+	ids := recordIDs()
+	assert.NotEmpty(t, ids[0])
+	assert.NotEqual(t, ids[0], ids[1])
+	// the IDs are the same when the event is sent again
+	assert.Equal(t, ids, recordIDs())
+}
+
+func TestSplitEventsPartialFailure(t *testing.T) {
+	var posted int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&posted, 1)
+		b, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(b), `"EventId":"2162"`) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	prevURL, prevClient, prevSplit := baseURL, restClient, splitEvents
+	publishers[bmc.DefaultID].pub = pubsub.PubSub{ID: "test-publisher"}
+	baseURL, restClient, splitEvents = types.ParseURI(server.URL+"/"), restclient.New(time.Second), true
+	defer func() {
+		publishers[bmc.DefaultID].pub = pubsub.PubSub{}
+		baseURL, restClient, splitEvents = prevURL, prevClient, prevSplit
+	}()
+
+	// the second record is published although the first one failed
+	err := handleHwEvent(context.Background(), bmc.DefaultID, []byte(testEventTwoRecords))
+	assert.ErrorIs(t, err, errPublishFailed)
+	assert.Contains(t, err.Error(), "records [0] of hw event 5e004f5a-e3d1-11eb-ae9c-3448edf18a38 not published, records [1] published")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&posted))
+}
+
+func TestHwEventID(t *testing.T) {
+	p := publishers[bmc.DefaultID]
+	e := redfish.Event{ID: "1"}
+	records := []eventrecord.Fields{{RecordID: "a"}}
+	assert.NotEqual(t, hwEventID(p, e, records), hwEventID(p, e, records))
+
+	deterministicIDs = true
+	defer func() { deterministicIDs = false }()
+	id := hwEventID(p, e, records)
+	assert.Equal(t, id, hwEventID(p, e, records))
+	assert.NotEqual(t, id, hwEventID(p, e, []eventrecord.Fields{{RecordID: "b"}}))
+}
+
+func TestRecordEventID(t *testing.T) {
+	p := publishers[bmc.DefaultID]
+	e := redfish.Event{ID: "1"}
+	r := redfish.EventRecord{EventID: "2162", MemberID: "0"}
+	now := time.Now()
+	id := recordEventID(p, e, r, now)
+	assert.Equal(t, id, recordEventID(p, e, r, now))
+	r.EventID = "2163"
+	assert.NotEqual(t, id, recordEventID(p, e, r, now))
+
+	// records without EventId of events without Id are told apart by the receive time
+	r.EventID = ""
+	assert.Equal(t, recordEventID(p, e, r, now), recordEventID(p, e, r, now.Add(time.Second)))
+	e.ID = ""
+	assert.NotEqual(t, recordEventID(p, e, r, now), recordEventID(p, e, r, now.Add(time.Second)))
+}
+
+func TestClockSkew(t *testing.T) {
+	p := &bmcPublisher{BMC: bmc.BMC{ID: "skew"}}
+	now := time.Date(2021, 7, 13, 12, 8, 0, 0, time.UTC)
+	skews := atomic.LoadUint64(&clockSkews)
+
+	// the latest EventTimestamp is compared with the receive time
+	checkClockSkew(p, now, []redfish.EventRecord{{EventTimestamp: "2021-07-13T11:00:00Z"}, {EventTimestamp: "2021-07-13T15:07:59+0300"}})
+	assert.False(t, p.clockSkewed.Load())
+	checkClockSkew(p, now, []redfish.EventRecord{{}})
+	assert.False(t, p.clockSkewed.Load())
+
+	checkClockSkew(p, now, []redfish.EventRecord{{EventTimestamp: "2021-07-13T15:07:59Z"}})
+	assert.True(t, p.clockSkewed.Load())
+	assert.Equal(t, skews+1, atomic.LoadUint64(&clockSkews))
+	checkClockSkew(p, now, []redfish.EventRecord{{EventTimestamp: "2021-07-13T12:07:59Z"}})
+	assert.False(t, p.clockSkewed.Load())
+
+	h := hwEvent{receivedAt: now}
+	assert.Equal(t, now, eventTime(h, []redfish.EventRecord{{EventTimestamp: "unknown"}}))
+	assert.Equal(t, time.Date(2021, 7, 13, 11, 0, 0, 0, time.UTC),
+		eventTime(h, []redfish.EventRecord{{EventTimestamp: "2021-07-13T12:07:59Z"}, {EventTimestamp: "2021-07-13T07:00:00-0400"}}))
+}
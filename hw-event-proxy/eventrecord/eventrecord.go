@@ -0,0 +1,151 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package eventrecord keeps the EventRecord properties of Event v1_4 and later
+// that redfish.EventRecord does not model, so they can be forwarded with the event
+// in the Oem object of the records, and the properties the proxy adds to the event.
+package eventrecord
+
+import (
+	"fmt"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/redhat-cne/sdk-go/pkg/event/redfish"
+
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/registry"
+)
+
+// OemKey is the key of the proxy properties in the Oem object of an event and its records. The sidecar decodes the
+// published event into redfish.EventRecord, which drops the unknown properties but keeps Oem.
+const OemKey = "HwEventProxy"
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// Fields are the properties of a record dropped by redfish.EventRecord
+type Fields struct {
+	// EventGroupID is nil when the BMC does not group the event,
+	// redfish.EventRecord can't tell an absent group from group 0
+	EventGroupID *int `json:"EventGroupId,omitempty"`
+	// MessageSeverity is the severity of the message, Severity is deprecated in its favor
+	MessageSeverity string `json:"MessageSeverity,omitempty"`
+	// LogEntry is the link to the log entry created for the event
+	LogEntry jsoniter.RawMessage `json:"LogEntry,omitempty"`
+	// SpecificEventExistsInGroup tells that the group holds a more specific event
+	SpecificEventExistsInGroup *bool `json:"SpecificEventExistsInGroup,omitempty"`
+	// DiagnosticData is the base64 encoded diagnostic data of the event
+	DiagnosticData        string `json:"DiagnosticData,omitempty"`
+	DiagnosticDataType    string `json:"DiagnosticDataType,omitempty"`
+	OEMDiagnosticDataType string `json:"OEMDiagnosticDataType,omitempty"`
+	// AdditionalDataURI is the URI of the diagnostic data too large to be sent with the event
+	AdditionalDataURI       string `json:"AdditionalDataURI,omitempty"`
+	AdditionalDataSizeBytes *int64 `json:"AdditionalDataSizeBytes,omitempty"`
+	// ResolutionSteps are the structured steps to resolve the condition, Resolution is kept by redfish.EventRecord
+	ResolutionSteps jsoniter.RawMessage `json:"ResolutionSteps,omitempty"`
+	// MessageRegistry is the registry message the proxy resolved the MessageId with
+	MessageRegistry *registry.Metadata `json:"MessageRegistry,omitempty"`
+	// RecordID is the ID the proxy gives to the record,
+	// the same record gets the same ID when the BMC sends it again
+	RecordID string `json:"RecordId,omitempty"`
+}
+
+// EventFields are the properties the proxy adds to a Redfish event
+type EventFields struct {
+	// UniqueID is the ID the proxy gives to the event, the sidecar replaces the ID of the cloud event
+	UniqueID string `json:"UniqueId,omitempty"`
+	// ReceivedTime is the time the proxy received the event
+	ReceivedTime string `json:"ReceivedTime,omitempty"`
+}
+
+// Event is a Redfish event with the additional properties of its records
+type Event struct {
+	Redfish redfish.Event
+	// Records holds the Fields of every record of Redfish.Events, in the same order
+	Records []Fields
+}
+
+// Decode unmarshals a Redfish event and the additional properties of its records
+func Decode(b []byte) (Event, error) {
+	e := Event{}
+	if err := json.Unmarshal(b, &e.Redfish); err != nil {
+		return e, err
+	}
+	var records struct {
+		Events []Fields `json:"Events"`
+	}
+	if err := json.Unmarshal(b, &records); err != nil {
+		return e, err
+	}
+	e.Records = records.Events
+	return e, nil
+}
+
+// DeriveSeverity sets the Severity of the records without one from their MessageSeverity,
+// both properties use the OK, Warning and Critical values
+func (e *Event) DeriveSeverity() {
+	for i := range e.Redfish.Events {
+		r := &e.Redfish.Events[i]
+		if r.Severity == "" && i < len(e.Records) {
+			r.Severity = e.Records[i].MessageSeverity
+		}
+	}
+}
+
+// SetOem returns a copy of the records with the fields of every record added to its Oem object under OemKey,
+// the Oem properties set by the BMC are kept
+func SetOem(records []redfish.EventRecord, fields []Fields) ([]redfish.EventRecord, error) {
+	out := make([]redfish.EventRecord, len(records))
+	copy(out, records)
+	for i := range out {
+		if i >= len(fields) {
+			break
+		}
+		oem, err := fields[i].addTo(out[i].Oem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add the fields of Events[%d]: %v", i, err)
+		}
+		out[i].Oem = oem
+	}
+	return out, nil
+}
+
+// SetEventOem returns the event with the fields added to its Oem object under OemKey,
+// the Oem properties set by the BMC are kept
+func SetEventOem(e redfish.Event, fields EventFields) (redfish.Event, error) {
+	oem, err := addOem(e.Oem, fields)
+	if err != nil {
+		return e, fmt.Errorf("failed to add the fields of the event: %v", err)
+	}
+	e.Oem = oem
+	return e, nil
+}
+
+func (f Fields) addTo(oem []byte) ([]byte, error) {
+	return addOem(oem, f)
+}
+
+// addOem adds the fields to an Oem object, oem is returned as is when no field is set
+func addOem(oem []byte, fields interface{}) ([]byte, error) {
+	b, err := json.Marshal(fields)
+	if err != nil || string(b) == "{}" {
+		return oem, err
+	}
+	properties := map[string]jsoniter.RawMessage{}
+	if len(oem) > 0 {
+		if err = json.Unmarshal(oem, &properties); err != nil {
+			return nil, fmt.Errorf("Oem is not an object: %v", err)
+		}
+	}
+	properties[OemKey] = b
+	return json.Marshal(properties)
+}
@@ -0,0 +1,115 @@
+//go:build unittests
+// +build unittests
+
+package eventrecord
+
+import (
+	"testing"
+
+	"github.com/redhat-cne/sdk-go/pkg/event"
+	"github.com/redhat-cne/sdk-go/pkg/event/redfish"
+	v1event "github.com/redhat-cne/sdk-go/v1/event"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/registry"
+)
+
+const newerEvent = `{
+	"@odata.type": "#Event.v1_7_0.Event",
+	"Id": "1",
+	"Name": "Event Array",
+	"Events": [{
+		"EventType": "Alert",
+		"MemberId": "0",
+		"MessageId": "Resource.1.0.ResourceErrorsDetected",
+		"MessageArgs": ["PSU1", "overcurrent"],
+		"MessageSeverity": "Critical",
+		"EventGroupId": 0,
+		"SpecificEventExistsInGroup": false,
+		"LogEntry": {"@odata.id": "/redfish/v1/Systems/1/LogServices/Sel/Entries/7"},
+		"DiagnosticDataType": "CPER",
+		"DiagnosticData": "Q1BFUg==",
+		"ResolutionSteps": [{"Priority": 0, "ResolutionType": "ReplaceComponent"}],
+		"Oem": {"Dell": {"Category": "System Health"}}
+	}, {
+		"EventType": "Alert",
+		"MemberId": "1",
+		"MessageId": "TMP0100",
+		"Severity": "OK",
+		"MessageSeverity": "Warning"
+	}]
+}`
+
+func TestDecode(t *testing.T) {
+	e, err := Decode([]byte(newerEvent))
+	assert.NoError(t, err)
+	assert.Len(t, e.Records, 2)
+	assert.Equal(t, 0, *e.Records[0].EventGroupID)
+	assert.Nil(t, e.Records[1].EventGroupID)
+	assert.Equal(t, "CPER", e.Records[0].DiagnosticDataType)
+
+	e.DeriveSeverity()
+	assert.Equal(t, "Critical", e.Redfish.Events[0].Severity)
+	// Severity set by the BMC is kept
+	assert.Equal(t, "OK", e.Redfish.Events[1].Severity)
+}
+
+func TestSetOem(t *testing.T) {
+	e, err := Decode([]byte(newerEvent))
+	assert.NoError(t, err)
+	e.Records[1].MessageRegistry = &registry.Metadata{RegistryPrefix: "IDRAC", RegistryVersion: "2.8.0", MessageKey: "TMP0100", NumberOfArgs: 1}
+	records, err := SetOem(e.Redfish.Events, e.Records)
+	assert.NoError(t, err)
+	// the records of the event are not modified
+	assert.Nil(t, e.Redfish.Events[1].Oem)
+
+	redfishEvent := e.Redfish
+	redfishEvent.Events = records
+	redfishEvent, err = SetEventOem(redfishEvent, EventFields{ReceivedTime: "2021-07-13T12:08:00Z"})
+	assert.NoError(t, err)
+	ce := v1event.CloudNativeEvent()
+	ce.ID = "1"
+	ce.Type = "event.redfish.alert"
+	ce.Source = "/cluster/node/worker-0/redfish/v1/Systems"
+	ce.SetDataContentType(event.ApplicationJSON)
+	data := v1event.CloudNativeData()
+	data.SetVersion("v1")                                                                            //nolint:errcheck
+	data.AppendValues(event.DataValue{Resource: "/redfish/v1/Systems", DataType: event.NOTIFICATION, //nolint:errcheck
+		ValueType: event.REDFISH_EVENT, Value: redfishEvent})
+	ce.SetData(data)
+	b, err := json.Marshal(ce)
+	assert.NoError(t, err)
+
+	// the sidecar decodes the posted event into event.Event
+	out := event.Event{}
+	assert.NoError(t, json.Unmarshal(b, &out))
+	published, ok := out.Data.Values[0].Value.(redfish.Event)
+	assert.True(t, ok)
+	assert.JSONEq(t, `{"HwEventProxy": {"ReceivedTime": "2021-07-13T12:08:00Z"}}`, string(published.Oem))
+	var oem []map[string]map[string]interface{}
+	for _, r := range published.Events {
+		o := map[string]map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(r.Oem, &o))
+		oem = append(oem, o)
+	}
+	assert.Equal(t, "System Health", oem[0]["Dell"]["Category"])
+	fields := oem[0][OemKey]
+	assert.Equal(t, "Critical", fields["MessageSeverity"])
+	assert.Equal(t, float64(0), fields["EventGroupId"])
+	assert.Equal(t, false, fields["SpecificEventExistsInGroup"])
+	assert.Equal(t, "Q1BFUg==", fields["DiagnosticData"])
+	assert.NotNil(t, fields["LogEntry"])
+	assert.NotNil(t, fields["ResolutionSteps"])
+	// the record was not in a group
+	assert.NotContains(t, oem[1][OemKey], "EventGroupId")
+	assert.Equal(t, "Warning", oem[1][OemKey]["MessageSeverity"])
+	assert.Equal(t, map[string]interface{}{"RegistryPrefix": "IDRAC", "RegistryVersion": "2.8.0", "MessageKey": "TMP0100",
+		"NumberOfArgs": float64(1)}, oem[1][OemKey]["MessageRegistry"])
+
+	// records without fields are not changed
+	records, err = SetOem([]redfish.EventRecord{{MemberID: "0"}}, []Fields{{}})
+	assert.NoError(t, err)
+	assert.Nil(t, records[0].Oem)
+	_, err = SetOem([]redfish.EventRecord{{Oem: []byte(`"Dell"`)}}, []Fields{{MessageSeverity: "OK"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventrecord
+
+import (
+	"strings"
+	"time"
+
+	"github.com/redhat-cne/sdk-go/pkg/event/redfish"
+)
+
+// timestampLayouts are the EventTimestamp formats sent by BMCs, timestamps without
+// time zone are in UTC
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// ParseTimestamp parses an EventTimestamp, e.g. 2021-07-06T01:17:12-04:00, 2021-07-06T01:17:12-0400
+// or 2021-07-06T05:17:12Z. ok is false for empty or unknown timestamps.
+func ParseTimestamp(s string) (t time.Time, ok bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// EarliestTimestamp returns the earliest EventTimestamp of the records,
+// ok is false when no record has a valid timestamp
+func EarliestTimestamp(records []redfish.EventRecord) (earliest time.Time, ok bool) {
+	for _, r := range records {
+		if t, valid := ParseTimestamp(r.EventTimestamp); valid && (!ok || t.Before(earliest)) {
+			earliest, ok = t, true
+		}
+	}
+	return earliest, ok
+}
@@ -0,0 +1,50 @@
+//go:build unittests
+// +build unittests
+
+package eventrecord
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redhat-cne/sdk-go/pkg/event/redfish"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2021, 7, 6, 5, 17, 12, 0, time.UTC)
+	for _, s := range []string{
+		"2021-07-06T01:17:12-04:00",
+		"2021-07-06T01:17:12-0400",
+		"2021-07-06T05:17:12Z",
+		"2021-07-06T05:17:12+0000",
+		"2021-07-06T05:17:12",
+		"2021-07-06 05:17:12Z",
+		" 2021-07-06T05:17:12Z ",
+	} {
+		ts, ok := ParseTimestamp(s)
+		assert.True(t, ok, s)
+		assert.True(t, want.Equal(ts), s)
+	}
+	ts, ok := ParseTimestamp("2021-07-06T05:17:12.250+00:00")
+	assert.True(t, ok)
+	assert.Equal(t, 250*time.Millisecond, ts.Sub(want))
+
+	for _, s := range []string{"", "yesterday", "2021-07-06"} {
+		_, ok = ParseTimestamp(s)
+		assert.False(t, ok, s)
+	}
+}
+
+func TestEarliestTimestamp(t *testing.T) {
+	ts, ok := EarliestTimestamp([]redfish.EventRecord{
+		{EventTimestamp: "2021-07-06T05:17:12Z"},
+		{},
+		{EventTimestamp: "2021-07-06T01:16:12-0400"},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2021, 7, 6, 5, 16, 12, 0, time.UTC), ts)
+
+	_, ok = EarliestTimestamp([]redfish.EventRecord{{EventTimestamp: "unknown"}})
+	assert.False(t, ok)
+}
@@ -0,0 +1,364 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/redhat-cne/sdk-go/pkg/event/redfish"
+	"github.com/redhat-cne/sdk-go/pkg/util/wait"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/bmc"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/eventrecord"
+)
+
+const (
+	// eventOdataType is the @odata.type of the events created from log entries
+	eventOdataType = "#Event.v1_3_0.Event"
+	eventName      = "Log Service Entries"
+	// eventType is the EventType of the records created from log entries
+	eventType      = "Alert"
+	requestTimeout = 30 * time.Second
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// Handler is called with the new entries of a log service converted to a Redfish event,
+// the entries are read again by the next poll when it returns an error. ctx is canceled when the poller is stopped.
+type Handler func(ctx context.Context, e eventrecord.Event) error
+
+// LogEntry is the part of a Redfish LogEntry converted to an event record
+type LogEntry struct {
+	OdataID     string              `json:"@odata.id"`
+	ID          string              `json:"Id"`
+	Created     string              `json:"Created"`
+	EntryType   string              `json:"EntryType"`
+	Message     string              `json:"Message"`
+	MessageID   string              `json:"MessageId"`
+	MessageArgs []string            `json:"MessageArgs"`
+	Severity    string              `json:"Severity"`
+	Resolution  string              `json:"Resolution"`
+	Links       *LogEntryLinks      `json:"Links,omitempty"`
+	Oem         jsoniter.RawMessage `json:"Oem,omitempty"`
+	// diagnostic data of LogEntry v1_7 and later
+	DiagnosticDataType      string `json:"DiagnosticDataType"`
+	OEMDiagnosticDataType   string `json:"OEMDiagnosticDataType"`
+	AdditionalDataURI       string `json:"AdditionalDataURI"`
+	AdditionalDataSizeBytes *int64 `json:"AdditionalDataSizeBytes,omitempty"`
+}
+
+// LogEntryLinks are the links of a LogEntry
+type LogEntryLinks struct {
+	OriginOfCondition jsoniter.RawMessage `json:"OriginOfCondition,omitempty"`
+}
+
+// collection is a page of a Redfish resource collection
+type collection struct {
+	Members []jsoniter.RawMessage `json:"Members"`
+	// NextLink is the URI of the next page
+	NextLink string `json:"Members@odata.nextLink"`
+}
+
+// member is a link to a collection member
+type member struct {
+	ID string `json:"@odata.id"`
+}
+
+// cursor is the position in a log service, entries created after
+// Created, or at Created with an ID not in IDs, are new
+type cursor struct {
+	Created time.Time `json:"created"`
+	IDs     []string  `json:"ids"`
+	// Undated are the IDs of the entries in the log service without valid Created time
+	Undated []string `json:"undated,omitempty"`
+}
+
+// Poller reads new entries from the log services of a BMC that can't push events
+type Poller struct {
+	bmcID      string
+	conn       *bmc.Connection
+	httpClient *http.Client
+	// paths of the entries collections, a path segment can be * to watch all the members of a collection,
+	// e.g. /redfish/v1/Systems/*/LogServices/*/Entries
+	paths      []string
+	interval   time.Duration
+	cursorFile string
+	cursors    map[string]cursor
+	handler    Handler
+}
+
+// NewPoller creates a poller, the cursors are persisted in storePath
+func NewPoller(bmcID string, conn *bmc.Connection, paths []string, interval time.Duration, storePath string, handler Handler) *Poller {
+	p := &Poller{
+		bmcID:      bmcID,
+		conn:       conn,
+		httpClient: conn.HTTPClient(requestTimeout),
+		paths:      paths,
+		interval:   interval,
+		cursorFile: filepath.Join(storePath, fmt.Sprintf("logservice-%s.json", bmcID)),
+		cursors:    map[string]cursor{},
+		handler:    handler,
+	}
+	if b, err := os.ReadFile(p.cursorFile); err == nil {
+		if err = json.Unmarshal(b, &p.cursors); err != nil {
+			log.Errorf("failed to unmarshal log service cursors %s: %v", p.cursorFile, err)
+		}
+	} else if !os.IsNotExist(err) {
+		log.Errorf("failed to read log service cursors %s: %v", p.cursorFile, err)
+	}
+	return p
+}
+
+// Run polls the log services every interval until stopCh is closed
+func (p *Poller) Run(stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+	wait.UntilWithContext(ctx, p.Poll, p.interval)
+}
+
+// Poll reads the new entries of every log service and saves the cursors
+func (p *Poller) Poll(ctx context.Context) {
+	changed := false
+	for _, pattern := range p.paths {
+		paths, err := p.expand(ctx, pattern)
+		if err != nil {
+			log.Errorf("failed to expand log service %s of BMC %s: %v", pattern, p.bmcID, err)
+			continue
+		}
+		for _, path := range paths {
+			updated, pollErr := p.pollEntries(ctx, path)
+			if pollErr != nil {
+				log.Errorf("failed to poll log service %s of BMC %s: %v", path, p.bmcID, pollErr)
+			}
+			changed = changed || updated
+		}
+	}
+	if changed {
+		p.saveCursors()
+	}
+}
+
+// expand replaces the * segments of a path with the members of the matching collections
+func (p *Poller) expand(ctx context.Context, pattern string) ([]string, error) {
+	i := strings.Index(pattern, "/*")
+	if i < 0 {
+		return []string{pattern}, nil
+	}
+	members, err := p.members(ctx, pattern[:i])
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, m := range members {
+		expanded, expandErr := p.expand(ctx, strings.TrimSuffix(m, "/")+pattern[i+2:])
+		if expandErr != nil {
+			return nil, expandErr
+		}
+		paths = append(paths, expanded...)
+	}
+	return paths, nil
+}
+
+// members returns the @odata.id of the members of a collection
+func (p *Poller) members(ctx context.Context, path string) ([]string, error) {
+	var ids []string
+	for path != "" {
+		page := collection{}
+		if err := p.conn.Get(ctx, p.httpClient, path, &page); err != nil {
+			return nil, err
+		}
+		for _, raw := range page.Members {
+			m := member{}
+			if err := json.Unmarshal(raw, &m); err == nil && m.ID != "" {
+				ids = append(ids, m.ID)
+			}
+		}
+		path = page.NextLink
+	}
+	return ids, nil
+}
+
+// entries returns all the entries of a log service
+func (p *Poller) entries(ctx context.Context, path string) ([]LogEntry, error) {
+	var entries []LogEntry
+	for path != "" {
+		page := collection{}
+		if err := p.conn.Get(ctx, p.httpClient, path, &page); err != nil {
+			return nil, err
+		}
+		for _, raw := range page.Members {
+			entry := LogEntry{}
+			if err := json.Unmarshal(raw, &entry); err != nil {
+				log.Debugf("skipped log entry of %s: %v", path, err)
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		path = page.NextLink
+	}
+	return entries, nil
+}
+
+// pollEntries publishes the entries of a log service created after its cursor, the cursor only
+// advances when the handler succeeds. The first poll of a log service only sets the cursor,
+// older entries are not published. Entries without valid Created time are published once,
+// with the receive time as EventTimestamp.
+func (p *Poller) pollEntries(ctx context.Context, path string) (updated bool, err error) {
+	entries, err := p.entries(ctx, path)
+	if err != nil {
+		return false, err
+	}
+	receivedAt := time.Now().UTC().Format(time.RFC3339)
+	c, found := p.cursors[path]
+	var newEntries, undatedEntries []LogEntry
+	var undated []string
+	for _, entry := range entries {
+		created, ok := eventrecord.ParseTimestamp(entry.Created)
+		if !ok {
+			undated = append(undated, entry.ID)
+			if found && !contains(c.Undated, entry.ID) {
+				log.Warnf("log entry %s of %s has an invalid Created time %q, the receive time is used", entry.ID, path, entry.Created)
+				entry.Created = receivedAt
+				undatedEntries = append(undatedEntries, entry)
+			}
+			continue
+		}
+		if created.Before(c.Created) || (created.Equal(c.Created) && contains(c.IDs, entry.ID)) {
+			continue
+		}
+		newEntries = append(newEntries, entry)
+	}
+	if len(newEntries) == 0 && len(undatedEntries) == 0 {
+		if !found {
+			// empty log service, all the entries of the next poll are new
+			c.Undated = undated
+			p.cursors[path] = c
+			return true, nil
+		}
+		return false, nil
+	}
+	// log services list the entries newest or oldest first, publish the oldest first
+	sort.SliceStable(newEntries, func(i, j int) bool {
+		ti, _ := eventrecord.ParseTimestamp(newEntries[i].Created)
+		tj, _ := eventrecord.ParseTimestamp(newEntries[j].Created)
+		return ti.Before(tj)
+	})
+
+	next := c
+	for _, entry := range newEntries {
+		created, _ := eventrecord.ParseTimestamp(entry.Created)
+		if created.After(next.Created) {
+			next = cursor{Created: created}
+		}
+		next.IDs = append(next.IDs, entry.ID)
+	}
+	next.Undated = undated
+	if !found {
+		p.cursors[path] = next
+		if len(newEntries) > 0 {
+			log.Infof("log service %s of BMC %s starts after entry %s", path, p.bmcID, newEntries[len(newEntries)-1].ID)
+		}
+		return true, nil
+	}
+	newEntries = append(newEntries, undatedEntries...)
+	log.Debugf("read %d new entries from log service %s of BMC %s", len(newEntries), path, p.bmcID)
+	if err = p.handler(ctx, ToEvent(path, newEntries)); err != nil {
+		return false, err
+	}
+	p.cursors[path] = next
+	return true, nil
+}
+
+func (p *Poller) saveCursors() {
+	b, err := json.Marshal(p.cursors)
+	if err != nil {
+		log.Errorf("failed to marshal log service cursors: %v", err)
+		return
+	}
+	tmp := p.cursorFile + ".tmp"
+	if err = os.WriteFile(tmp, b, 0600); err == nil {
+		err = os.Rename(tmp, p.cursorFile)
+	}
+	if err != nil {
+		log.Errorf("failed to save log service cursors %s: %v", p.cursorFile, err)
+	}
+}
+
+// ToEvent converts log entries to a Redfish event with one record per entry,
+// every record links to its log entry
+func ToEvent(path string, entries []LogEntry) eventrecord.Event {
+	e := redfish.Event{
+		OdataType: eventOdataType,
+		ID:        fmt.Sprintf("%s/%s", path, entries[len(entries)-1].ID),
+		Name:      eventName,
+	}
+	var records []eventrecord.Fields
+	for i, entry := range entries {
+		r := redfish.EventRecord{
+			EventID:        entry.ID,
+			EventTimestamp: entry.Created,
+			EventType:      eventType,
+			MemberID:       strconv.Itoa(i),
+			Message:        entry.Message,
+			MessageArgs:    entry.MessageArgs,
+			MessageID:      entry.MessageID,
+			Severity:       entry.Severity,
+			Resolution:     entry.Resolution,
+		}
+		if entry.Links != nil && len(entry.Links.OriginOfCondition) > 0 {
+			r.OriginOfCondition = entry.Links.OriginOfCondition
+		}
+		if len(entry.Oem) > 0 {
+			r.Oem = entry.Oem
+		}
+		e.Events = append(e.Events, r)
+
+		uri := entry.OdataID
+		if uri == "" {
+			uri = fmt.Sprintf("%s/%s", path, entry.ID)
+		}
+		logEntry, _ := json.Marshal(map[string]string{"@odata.id": uri})
+		records = append(records, eventrecord.Fields{
+			LogEntry:                logEntry,
+			DiagnosticDataType:      entry.DiagnosticDataType,
+			OEMDiagnosticDataType:   entry.OEMDiagnosticDataType,
+			AdditionalDataURI:       entry.AdditionalDataURI,
+			AdditionalDataSizeBytes: entry.AdditionalDataSizeBytes,
+		})
+	}
+	return eventrecord.Event{Redfish: e, Records: records}
+}
+
+func contains(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+//go:build unittests
+// +build unittests
+
+package logservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/bmc"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/eventrecord"
+)
+
+func TestPoller(t *testing.T) {
+	entries := []string{
+		`{"Id": "1", "Created": "2021-07-06T01:17:12-0400", "MessageId": "TMP0120", "Severity": "Warning"}`,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/redfish/v1/Systems", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Members": [{"@odata.id": "/redfish/v1/Systems/1"}]}`)
+	})
+	mux.HandleFunc("/redfish/v1/Systems/1/LogServices/Sel/Entries", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"Members": [%s]}`, strings.Join(entries, ","))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var events []eventrecord.Event
+	store := t.TempDir()
+	conn := &bmc.Connection{Address: server.URL}
+	var handlerErr error
+	p := NewPoller("test", conn, []string{"/redfish/v1/Systems/*/LogServices/Sel/Entries"}, 0, store, func(_ context.Context, e eventrecord.Event) error {
+		if handlerErr != nil {
+			return handlerErr
+		}
+		events = append(events, e)
+		return nil
+	})
+
+	// the first poll only sets the cursor
+	p.Poll(context.Background())
+	assert.Empty(t, events)
+
+	// newest first, as listed by some BMCs
+	entries = append([]string{
+		`{"Id": "3", "Created": "2021-07-06T05:20:00Z", "MessageId": "FAN0001", "MessageArgs": ["1"], "Severity": "Critical"}`,
+		`{"Id": "2", "Created": "2021-07-06T01:17:12-04:00", "MessageId": "TMP0100", "Severity": "OK"}`,
+	}, entries...)
+	// the cursor doesn't advance when the entries are not published
+	handlerErr = errors.New("publish failed")
+	p.Poll(context.Background())
+	assert.Empty(t, events)
+	handlerErr = nil
+	p.Poll(context.Background())
+	assert.Len(t, events, 1)
+	records := events[0].Redfish.Events
+	assert.Len(t, records, 2)
+	assert.Equal(t, "2", records[0].EventID)
+	assert.Equal(t, "3", records[1].EventID)
+	assert.Equal(t, "FAN0001", records[1].MessageID)
+	assert.Equal(t, []string{"1"}, records[1].MessageArgs)
+	assert.Equal(t, "2021-07-06T05:20:00Z", records[1].EventTimestamp)
+	assert.JSONEq(t, `{"@odata.id": "/redfish/v1/Systems/1/LogServices/Sel/Entries/3"}`, string(events[0].Records[1].LogEntry))
+
+	// the cursor is persisted across restarts
+	p = NewPoller("test", conn, []string{"/redfish/v1/Systems/*/LogServices/Sel/Entries"}, 0, store, func(_ context.Context, e eventrecord.Event) error {
+		events = append(events, e)
+		return nil
+	})
+	p.Poll(context.Background())
+	assert.Len(t, events, 1)
+
+	// entries without valid Created time are published once with the receive time
+	entries = append(entries, `{"Id": "4", "Created": "unknown", "MessageId": "PSU0001", "Severity": "Critical"}`)
+	p.Poll(context.Background())
+	p.Poll(context.Background())
+	assert.Len(t, events, 2)
+	records = events[1].Redfish.Events
+	assert.Len(t, records, 1)
+	assert.Equal(t, "4", records[0].EventID)
+	_, ok := eventrecord.ParseTimestamp(records[0].EventTimestamp)
+	assert.True(t, ok)
+}
@@ -0,0 +1,161 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package msgcache caches the messages resolved from the MessageId and MessageArgs of event records.
+package msgcache
+
+import (
+	"container/list"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/registry"
+)
+
+// Entry is a cached message, Known is false for MessageIds not found in the registries
+type Entry struct {
+	Known bool
+	registry.Resolved
+}
+
+// Stats is a snapshot of the cache counters
+type Stats struct {
+	Size         int    `json:"size"`
+	Capacity     int    `json:"capacity"`
+	Hits         uint64 `json:"hits"`
+	NegativeHits uint64 `json:"negativeHits"`
+	Misses       uint64 `json:"misses"`
+	Expired      uint64 `json:"expired"`
+	Evicted      uint64 `json:"evicted"`
+}
+
+type item struct {
+	key     string
+	entry   Entry
+	expires time.Time
+}
+
+// Cache is a bounded LRU cache of messages. Entries expire after ttl,
+// or negativeTTL for unknown messages so that registries loaded later are used.
+type Cache struct {
+	mu          sync.Mutex
+	capacity    int
+	ttl         time.Duration
+	negativeTTL time.Duration
+	items       map[string]*list.Element
+	lru         *list.List
+	stats       Stats
+	now         func() time.Time
+}
+
+// New creates a cache holding up to capacity messages
+func New(capacity int, ttl, negativeTTL time.Duration) *Cache {
+	return &Cache{
+		capacity:    capacity,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		items:       map[string]*list.Element{},
+		lru:         list.New(),
+		now:         time.Now,
+	}
+}
+
+// Key returns the cache key of a MessageId and its arguments
+func Key(messageID string, args []string) string {
+	b := strings.Builder{}
+	b.WriteString(messageID)
+	for _, arg := range args {
+		// length prefixed so that arguments containing the separator can't collide
+		b.WriteByte(0)
+		b.WriteString(strconv.Itoa(len(arg)))
+		b.WriteByte(':')
+		b.WriteString(arg)
+	}
+	return b.String()
+}
+
+// Get returns the cached message of a MessageId and its arguments
+func (c *Cache) Get(messageID string, args []string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[Key(messageID, args)]
+	if !ok {
+		c.stats.Misses++
+		return Entry{}, false
+	}
+	it := e.Value.(*item)
+	if c.now().After(it.expires) {
+		c.remove(e)
+		c.stats.Expired++
+		c.stats.Misses++
+		return Entry{}, false
+	}
+	c.lru.MoveToFront(e)
+	if it.entry.Known {
+		c.stats.Hits++
+	} else {
+		c.stats.NegativeHits++
+	}
+	return it.entry, true
+}
+
+// Add caches the message of a MessageId and its arguments, evicting the least recently used message when full
+func (c *Cache) Add(messageID string, args []string, entry Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.capacity <= 0 {
+		return
+	}
+	ttl := c.ttl
+	if !entry.Known {
+		ttl = c.negativeTTL
+	}
+	key := Key(messageID, args)
+	if e, ok := c.items[key]; ok {
+		it := e.Value.(*item)
+		it.entry, it.expires = entry, c.now().Add(ttl)
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.lru.PushFront(&item{key: key, entry: entry, expires: c.now().Add(ttl)})
+	for c.lru.Len() > c.capacity {
+		c.remove(c.lru.Back())
+		c.stats.Evicted++
+	}
+}
+
+// Purge removes all the messages, e.g. when new registries are loaded
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = map[string]*list.Element{}
+	c.lru.Init()
+}
+
+// Stats returns a snapshot of the counters
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Size = c.lru.Len()
+	s.Capacity = c.capacity
+	return s
+}
+
+func (c *Cache) remove(e *list.Element) {
+	c.lru.Remove(e)
+	delete(c.items, e.Value.(*item).key)
+}
@@ -0,0 +1,60 @@
+//go:build unittests
+// +build unittests
+
+package msgcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/registry"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Now()
+	c := New(2, time.Hour, time.Minute)
+	c.now = func() time.Time { return now }
+
+	_, ok := c.Get("TMP0100", []string{"Inlet"})
+	assert.False(t, ok)
+	c.Add("TMP0100", []string{"Inlet"}, Entry{Known: true, Resolved: registry.Resolved{Message: "Inlet temperature", Severity: "Warning"}})
+	c.Add("TMP0000", nil, Entry{})
+
+	e, ok := c.Get("TMP0100", []string{"Inlet"})
+	assert.True(t, ok)
+	assert.Equal(t, "Warning", e.Severity)
+	// the arguments are part of the key
+	_, ok = c.Get("TMP0100", []string{"Exhaust"})
+	assert.False(t, ok)
+	e, ok = c.Get("TMP0000", nil)
+	assert.True(t, ok)
+	assert.False(t, e.Known)
+
+	// negative entries expire first
+	now = now.Add(2 * time.Minute)
+	_, ok = c.Get("TMP0000", nil)
+	assert.False(t, ok)
+
+	// the least recently used message is evicted
+	c.Add("FAN0001", []string{"1"}, Entry{Known: true})
+	c.Add("FAN0001", []string{"2"}, Entry{Known: true})
+	_, ok = c.Get("TMP0100", []string{"Inlet"})
+	assert.False(t, ok)
+
+	s := c.Stats()
+	assert.Equal(t, 2, s.Size)
+	assert.Equal(t, uint64(1), s.Hits)
+	assert.Equal(t, uint64(1), s.NegativeHits)
+	assert.Equal(t, uint64(4), s.Misses)
+	assert.Equal(t, uint64(1), s.Expired)
+	assert.Equal(t, uint64(1), s.Evicted)
+
+	c.Purge()
+	assert.Equal(t, 0, c.Stats().Size)
+}
+
+func TestKey(t *testing.T) {
+	assert.NotEqual(t, Key("A", []string{"a\x001:b"}), Key("A", []string{"a", "b"}))
+}
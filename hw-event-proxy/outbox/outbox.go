@@ -0,0 +1,391 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package outbox persists the events to publish on disk and publishes them in order,
+// retrying with an exponential backoff until the publishing succeeds or is rejected.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/restclient"
+)
+
+const (
+	// reconnection backoff of the publishing retries
+	minBackoff = 1 * time.Second
+	maxBackoff = 60 * time.Second
+	// entryExt is the extension of the entry files
+	entryExt = ".json"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// Sender publishes an event, ctx is canceled when the outbox is stopped. An entry is removed from the outbox when it
+// returns nil, or a restclient.StatusError with a status that retrying doesn't fix if DropRejected is set
+type Sender func(ctx context.Context, event []byte) error
+
+// Stats is a snapshot of the outbox counters
+type Stats struct {
+	Pending   int    `json:"pending"`
+	Capacity  int    `json:"capacity"`
+	Persisted uint64 `json:"persisted"`
+	Sent      uint64 `json:"sent"`
+	Retries   uint64 `json:"retries"`
+	Dropped   uint64 `json:"dropped"`
+	Expired   uint64 `json:"expired"`
+	Rejected  uint64 `json:"rejected"`
+}
+
+// record is the content of an entry file
+type record struct {
+	Created time.Time           `json:"created"`
+	Event   jsoniter.RawMessage `json:"event"`
+}
+
+type entry struct {
+	path    string
+	created time.Time
+}
+
+// lane holds the entries of a BMC, which are sent one at a time in order
+type lane struct {
+	mu      sync.Mutex
+	dir     string
+	seq     uint64
+	entries []entry
+	notify  chan struct{}
+}
+
+// Outbox holds the events of every BMC in <dir>/<BMC ID>, one file per event.
+// The events of a BMC are sent in order, an event that fails to be sent is retried
+// before the next one. Up to size events are kept per BMC for up to maxAge.
+type Outbox struct {
+	// DropRejected drops the events rejected with a 4xx status other than 400, 404, 408 and 429
+	// instead of retrying them until they expire. The sidecar returns 400 and 404 for the events
+	// of a publisher it doesn't know yet, e.g. after a restart, before the publisher is created again.
+	DropRejected bool
+
+	dir    string
+	size   int
+	maxAge time.Duration
+	send   Sender
+
+	mu     sync.Mutex
+	lanes  map[string]*lane
+	stopCh <-chan struct{}
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	now        func() time.Time
+
+	persisted uint64
+	sent      uint64
+	retries   uint64
+	dropped   uint64
+	expired   uint64
+	rejected  uint64
+}
+
+// New creates an outbox in dir keeping up to size events per BMC for up to maxAge
+func New(dir string, size int, maxAge time.Duration, send Sender) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Outbox{
+		dir:        dir,
+		size:       size,
+		maxAge:     maxAge,
+		send:       send,
+		lanes:      map[string]*lane{},
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
+		now:        time.Now,
+	}, nil
+}
+
+// Start loads the events persisted before a restart and sends the events until stopCh is closed
+func (o *Outbox) Start(stopCh <-chan struct{}) error {
+	o.mu.Lock()
+	o.stopCh = stopCh
+	o.mu.Unlock()
+	dirs, err := os.ReadDir(o.dir)
+	if err != nil {
+		return err
+	}
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		l, err := o.lane(d.Name())
+		if err != nil {
+			return err
+		}
+		if len(l.entries) > 0 {
+			log.Infof("outbox has %d events of BMC %s to publish", len(l.entries), d.Name())
+		}
+	}
+	return nil
+}
+
+// Add persists the event of a BMC, it is sent after the previous events of the BMC.
+// The oldest event is dropped when the BMC has size events.
+func (o *Outbox) Add(bmcID string, event []byte) error {
+	l, err := o.lane(bmcID)
+	if err != nil {
+		return err
+	}
+	created := o.now()
+	b, err := json.Marshal(record{Created: created, Event: event})
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.seq++
+	path := filepath.Join(l.dir, fmt.Sprintf("%020d%s", l.seq, entryExt))
+	if err = writeFile(path, b); err != nil {
+		l.mu.Unlock()
+		return fmt.Errorf("failed to persist event: %v", err)
+	}
+	l.entries = append(l.entries, entry{path: path, created: created})
+	var dropped []entry
+	for len(l.entries) > o.size {
+		dropped = append(dropped, l.entries[0])
+		l.entries = l.entries[1:]
+	}
+	l.mu.Unlock()
+	atomic.AddUint64(&o.persisted, 1)
+	for _, e := range dropped {
+		atomic.AddUint64(&o.dropped, 1)
+		removeFile(e.path)
+		log.Warnf("outbox of BMC %s is full (%d), dropped the oldest event", bmcID, o.size)
+	}
+	select {
+	case l.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the outbox counters
+func (o *Outbox) Stats() Stats {
+	o.mu.Lock()
+	pending := 0
+	for _, l := range o.lanes {
+		l.mu.Lock()
+		pending += len(l.entries)
+		l.mu.Unlock()
+	}
+	o.mu.Unlock()
+	return Stats{
+		Pending:   pending,
+		Capacity:  o.size,
+		Persisted: atomic.LoadUint64(&o.persisted),
+		Sent:      atomic.LoadUint64(&o.sent),
+		Retries:   atomic.LoadUint64(&o.retries),
+		Dropped:   atomic.LoadUint64(&o.dropped),
+		Expired:   atomic.LoadUint64(&o.expired),
+		Rejected:  atomic.LoadUint64(&o.rejected),
+	}
+}
+
+// lane returns the lane of a BMC, loading its persisted entries and starting its sender the first time
+func (o *Outbox) lane(bmcID string) (*lane, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if l, ok := o.lanes[bmcID]; ok {
+		return l, nil
+	}
+	if o.stopCh == nil {
+		return nil, errors.New("outbox is not started")
+	}
+	l := &lane{dir: filepath.Join(o.dir, bmcID), notify: make(chan struct{}, 1)}
+	if err := os.MkdirAll(l.dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	o.lanes[bmcID] = l
+	go o.run(bmcID, l, o.stopCh)
+	return l, nil
+}
+
+// run sends the events of a lane in order until stopCh is closed
+func (o *Outbox) run(bmcID string, l *lane, stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+	backoff := o.minBackoff
+	for {
+		e, ok := l.head()
+		if !ok {
+			select {
+			case <-l.notify:
+				continue
+			case <-stopCh:
+				return
+			}
+		}
+		if o.now().Sub(e.created) > o.maxAge {
+			atomic.AddUint64(&o.expired, 1)
+			log.Warnf("dropped event of BMC %s persisted at %s, older than %s", bmcID, e.created.Format(time.RFC3339), o.maxAge)
+			l.remove(e)
+			continue
+		}
+		event, err := readEvent(e.path)
+		if err != nil {
+			log.Errorf("dropped unreadable outbox event %s: %v", e.path, err)
+			l.remove(e)
+			continue
+		}
+		if err = o.send(ctx, event); err != nil {
+			if !o.retryable(err) {
+				atomic.AddUint64(&o.rejected, 1)
+				log.Errorf("dropped event of BMC %s rejected by the sidecar: %v", bmcID, err)
+				l.remove(e)
+				backoff = o.minBackoff
+				continue
+			}
+			atomic.AddUint64(&o.retries, 1)
+			log.Warnf("failed to publish event of BMC %s: %v, retrying in %s", bmcID, err, backoff)
+			select {
+			case <-time.After(backoff):
+			case <-stopCh:
+				return
+			}
+			if backoff *= 2; backoff > o.maxBackoff {
+				backoff = o.maxBackoff
+			}
+			continue
+		}
+		atomic.AddUint64(&o.sent, 1)
+		l.remove(e)
+		backoff = o.minBackoff
+	}
+}
+
+// retryable tells if sending an event again may succeed, which is always assumed unless
+// DropRejected is set and the event was rejected with a 4xx status
+func (o *Outbox) retryable(err error) bool {
+	status := restclient.StatusCode(err)
+	if !o.DropRejected || status < http.StatusBadRequest || status >= http.StatusInternalServerError {
+		return true
+	}
+	switch status {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	return false
+}
+
+// load reads the entries persisted in the lane directory in order
+func (l *lane) load() error {
+	files, err := os.ReadDir(l.dir)
+	if err != nil {
+		return err
+	}
+	names := []string{}
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), entryExt) {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(l.dir, name)
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, entryExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		r := record{}
+		if err = json.Unmarshal(b, &r); err != nil {
+			log.Errorf("dropped unreadable outbox event %s: %v", path, err)
+			removeFile(path)
+			continue
+		}
+		l.entries = append(l.entries, entry{path: path, created: r.Created})
+		l.seq = seq
+	}
+	return nil
+}
+
+func (l *lane) head() (entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) == 0 {
+		return entry{}, false
+	}
+	return l.entries[0], true
+}
+
+// remove removes an entry, which may have been dropped in the meantime
+func (l *lane) remove(e entry) {
+	l.mu.Lock()
+	for i := range l.entries {
+		if l.entries[i].path == e.path {
+			l.entries = append(l.entries[:i], l.entries[i+1:]...)
+			break
+		}
+	}
+	l.mu.Unlock()
+	removeFile(e.path)
+}
+
+func readEvent(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := record{}
+	if err = json.Unmarshal(b, &r); err != nil {
+		return nil, err
+	}
+	return r.Event, nil
+}
+
+func removeFile(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Errorf("failed to remove outbox event %s: %v", path, err)
+	}
+}
+
+// writeFile writes a file atomically so that a crash doesn't leave a partial event
+func writeFile(path string, b []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,169 @@
+//go:build unittests
+// +build unittests
+
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/restclient"
+)
+
+// fakeSender records the sent events, fails while failing is set
+// and returns the status of the events in statuses
+type fakeSender struct {
+	mu       sync.Mutex
+	failing  bool
+	statuses map[string]int
+	sent     []string
+}
+
+func (s *fakeSender) send(_ context.Context, event []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failing {
+		return errors.New("dial tcp: connection refused")
+	}
+	if status, ok := s.statuses[string(event)]; ok {
+		return &restclient.StatusError{Method: http.MethodPost, URL: "create/event", StatusCode: status}
+	}
+	s.sent = append(s.sent, string(event))
+	return nil
+}
+
+func (s *fakeSender) setFailing(failing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing = failing
+}
+
+func (s *fakeSender) events() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.sent...)
+}
+
+func newOutbox(t *testing.T, dir string, size int, s *fakeSender) (*Outbox, chan struct{}) {
+	o, err := New(dir, size, time.Hour, s.send)
+	assert.NoError(t, err)
+	o.minBackoff, o.maxBackoff = time.Millisecond, 10*time.Millisecond
+	stopCh := make(chan struct{})
+	assert.NoError(t, o.Start(stopCh))
+	return o, stopCh
+}
+
+func TestOutboxRetriesInOrder(t *testing.T) {
+	s := &fakeSender{failing: true}
+	o, stopCh := newOutbox(t, t.TempDir(), 10, s)
+	defer close(stopCh)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, o.Add("bmc-1", []byte(fmt.Sprintf(`{"id":"%d"}`, i))))
+	}
+	assert.Eventually(t, func() bool { return o.Stats().Retries > 2 }, time.Second, time.Millisecond)
+	assert.Empty(t, s.events())
+
+	s.setFailing(false)
+	assert.Eventually(t, func() bool { return len(s.events()) == 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{`{"id":"0"}`, `{"id":"1"}`, `{"id":"2"}`}, s.events())
+	assert.Eventually(t, func() bool { return o.Stats().Pending == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, uint64(3), o.Stats().Sent)
+}
+
+func TestOutboxBadRequest(t *testing.T) {
+	// the sidecar returns 400 until the publisher is created again
+	s := &fakeSender{statuses: map[string]int{`{"id":"0"}`: http.StatusBadRequest}}
+	o, stopCh := newOutbox(t, t.TempDir(), 10, s)
+	defer close(stopCh)
+	o.DropRejected = true
+
+	assert.NoError(t, o.Add("bmc-1", []byte(`{"id":"0"}`)))
+	assert.Eventually(t, func() bool { return o.Stats().Retries > 0 }, time.Second, time.Millisecond)
+	s.mu.Lock()
+	delete(s.statuses, `{"id":"0"}`)
+	s.mu.Unlock()
+	assert.Eventually(t, func() bool { return len(s.events()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, uint64(0), o.Stats().Rejected)
+}
+
+func TestOutboxRejected(t *testing.T) {
+	s := &fakeSender{statuses: map[string]int{`{"id":"0"}`: http.StatusUnprocessableEntity, `{"id":"1"}`: http.StatusTooManyRequests}}
+	o, stopCh := newOutbox(t, t.TempDir(), 10, s)
+	defer close(stopCh)
+	o.DropRejected = true
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, o.Add("bmc-1", []byte(fmt.Sprintf(`{"id":"%d"}`, i))))
+	}
+	// the rejected event doesn't block the next ones, 429 is retried
+	assert.Eventually(t, func() bool { return o.Stats().Retries > 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, uint64(1), o.Stats().Rejected)
+	assert.Empty(t, s.events())
+	s.mu.Lock()
+	delete(s.statuses, `{"id":"1"}`)
+	s.mu.Unlock()
+	assert.Eventually(t, func() bool { return len(s.events()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{`{"id":"1"}`, `{"id":"2"}`}, s.events())
+}
+
+func TestOutboxRestart(t *testing.T) {
+	dir := t.TempDir()
+	s := &fakeSender{failing: true}
+	o, stopCh := newOutbox(t, dir, 2, s)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, o.Add("bmc-1", []byte(fmt.Sprintf(`{"id":"%d"}`, i))))
+	}
+	close(stopCh)
+	// the oldest event was dropped
+	assert.Equal(t, uint64(1), o.Stats().Dropped)
+
+	s = &fakeSender{}
+	o, stopCh = newOutbox(t, dir, 2, s)
+	defer close(stopCh)
+	assert.Eventually(t, func() bool { return len(s.events()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{`{"id":"1"}`, `{"id":"2"}`}, s.events())
+
+	// new events follow the persisted ones
+	assert.NoError(t, o.Add("bmc-1", []byte(`{"id":"3"}`)))
+	assert.Eventually(t, func() bool { return len(s.events()) == 3 }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		files, _ := filepath.Glob(filepath.Join(dir, "bmc-1", "*"))
+		return len(files) == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestOutboxMaxAge(t *testing.T) {
+	s := &fakeSender{failing: true}
+	o, err := New(t.TempDir(), 10, time.Minute, s.send)
+	assert.NoError(t, err)
+	o.minBackoff, o.maxBackoff = time.Millisecond, time.Millisecond
+	now := time.Now()
+	var mu sync.Mutex
+	o.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	assert.NoError(t, o.Start(stopCh))
+	assert.NoError(t, o.Add("bmc-1", []byte(`{"id":"0"}`)))
+
+	mu.Lock()
+	now = now.Add(2 * time.Minute)
+	mu.Unlock()
+	assert.Eventually(t, func() bool { return o.Stats().Expired == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, 0, o.Stats().Pending)
+	_, err = os.Stat(filepath.Join(o.dir, "bmc-1", fmt.Sprintf("%020d.json", 1)))
+	assert.True(t, os.IsNotExist(err))
+}
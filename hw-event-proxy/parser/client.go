@@ -0,0 +1,341 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package parser is the client of the message parser sidecar.
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/redhat-cne/sdk-go/pkg/event/redfish"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/pb"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/registry"
+)
+
+const (
+	// serviceName is the service whose health is watched
+	serviceName = "pb.MessageParser"
+	// reconnection backoff of the health watch
+	minBackoff = 1 * time.Second
+	maxBackoff = 30 * time.Second
+)
+
+var (
+	// ErrUnavailable is returned without calling the parser while it is not serving
+	ErrUnavailable = errors.New("message parser is not serving")
+	// ErrUnknownMessage is returned for messages not found in the registries
+	ErrUnknownMessage = errors.New("unable to find message in Redfish Registries")
+	// ErrInvalidArgument is returned for MessageArgs not matching the registry message
+	ErrInvalidArgument = errors.New("invalid message arguments")
+)
+
+// Stats are the counts of parse results
+type Stats struct {
+	Parsed          uint64 `json:"parsed"`
+	NotFound        uint64 `json:"notFound"`
+	Unavailable     uint64 `json:"unavailable"`
+	InvalidArgument uint64 `json:"invalidArgument"`
+	Failed          uint64 `json:"failed"`
+}
+
+// Client is a long-lived client of the message parser. The connection is reestablished
+// by gRPC and the health of the parser is watched with the standard gRPC health checking protocol.
+type Client struct {
+	conn    *grpc.ClientConn
+	parser  pb.MessageParserClient
+	health  healthpb.HealthClient
+	timeout time.Duration
+	serving atomic.Bool
+
+	parsed          atomic.Uint64
+	notFound        atomic.Uint64
+	unavailable     atomic.Uint64
+	invalidArgument atomic.Uint64
+	failed          atomic.Uint64
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// NewClient creates a client of the parser at addr, the connection is established in the background
+func NewClient(addr string, timeout time.Duration) (*Client, error) {
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		conn:       conn,
+		parser:     pb.NewMessageParserClient(conn),
+		health:     healthpb.NewHealthClient(conn),
+		timeout:    timeout,
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
+	}, nil
+}
+
+// Run watches the health of the parser until stopCh is closed, then closes the connection
+func (c *Client) Run(stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+	defer c.conn.Close()
+
+	backoff := c.minBackoff
+	for {
+		watched, err := c.watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if status.Code(err) == codes.Unimplemented {
+			// parsers without health service are assumed to be serving
+			c.setServing(true)
+			backoff = c.maxBackoff
+		} else {
+			c.setServing(false)
+			if watched {
+				backoff = c.minBackoff
+			}
+			log.Debugf("message parser health watch: %v, retrying in %s", err, backoff)
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		if backoff *= 2; backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+// watch updates the serving state with the health status sent by the parser until the stream ends,
+// watched is true if a status was received
+func (c *Client) watch(ctx context.Context) (watched bool, err error) {
+	stream, err := c.health.Watch(ctx, &healthpb.HealthCheckRequest{Service: serviceName})
+	if err != nil {
+		return false, err
+	}
+	for {
+		resp, recvErr := stream.Recv()
+		if recvErr != nil {
+			return watched, recvErr
+		}
+		watched = true
+		c.setServing(resp.Status == healthpb.HealthCheckResponse_SERVING)
+	}
+}
+
+func (c *Client) setServing(serving bool) {
+	if c.serving.Swap(serving) != serving {
+		if serving {
+			log.Info("message parser is serving")
+		} else {
+			log.Warn("message parser is not serving, messages are not parsed")
+		}
+	}
+}
+
+// Serving returns true if the parser reported that it is serving
+func (c *Client) Serving() bool {
+	return c.serving.Load()
+}
+
+// Stats returns the counts of parse results
+func (c *Client) Stats() Stats {
+	return Stats{
+		Parsed:          c.parsed.Load(),
+		NotFound:        c.notFound.Load(),
+		Unavailable:     c.unavailable.Load(),
+		InvalidArgument: c.invalidArgument.Load(),
+		Failed:          c.failed.Load(),
+	}
+}
+
+// Parse resolves the message, severity and resolution of a record from its MessageId.
+// ErrUnavailable is returned at once while the parser is not serving, the status codes
+// of the parser are returned as ErrUnknownMessage, ErrUnavailable and ErrInvalidArgument.
+func (c *Client) Parse(m redfish.EventRecord) (registry.Resolved, error) {
+	if !c.Serving() {
+		return registry.Resolved{}, c.count(ErrUnavailable)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	resp, err := c.parser.Parse(ctx, &pb.ParserRequest{
+		MessageId:   m.MessageID,
+		MessageArgs: m.MessageArgs,
+	})
+	if err != nil {
+		st, _ := status.FromError(err)
+		return registry.Resolved{}, c.count(statusError(st.Code(), st.Message(), m.MessageID))
+	}
+	return resolved(resp), c.count(nil)
+}
+
+// ParseBatch parses the records in one call. results and errs hold the result and the error
+// of every record, err is set when the call failed. The timeout applies to every record.
+func (c *Client) ParseBatch(records []redfish.EventRecord) (results []registry.Resolved, errs []error, err error) {
+	if !c.Serving() {
+		c.unavailable.Add(uint64(len(records)))
+		return nil, nil, ErrUnavailable
+	}
+	req := &pb.ParseBatchRequest{}
+	for _, m := range records {
+		req.Requests = append(req.Requests, &pb.ParserRequest{
+			MessageId:   m.MessageID,
+			MessageArgs: m.MessageArgs,
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout*time.Duration(len(records)))
+	defer cancel()
+	resp, err := c.parser.ParseBatch(ctx, req)
+	if status.Code(err) == codes.Unimplemented {
+		// parsers without the batch RPC
+		results, errs = c.parseEach(records)
+		return results, errs, nil
+	}
+	if err != nil {
+		st, _ := status.FromError(err)
+		err = statusError(st.Code(), st.Message(), "")
+		for range records {
+			c.count(err)
+		}
+		return nil, nil, err
+	}
+	if len(resp.Results) != len(records) {
+		c.failed.Add(uint64(len(records)))
+		return nil, nil, fmt.Errorf("message parser returned %d results for %d records", len(resp.Results), len(records))
+	}
+	results = make([]registry.Resolved, len(records))
+	errs = make([]error, len(records))
+	for i, result := range resp.Results {
+		switch {
+		case codes.Code(result.Code) != codes.OK:
+			errs[i] = statusError(codes.Code(result.Code), result.Error, records[i].MessageID)
+		case result.Response == nil:
+			errs[i] = fmt.Errorf("message parser returned no response for %s", records[i].MessageID)
+		default:
+			results[i] = resolved(result.Response)
+		}
+		c.count(errs[i])
+	}
+	return results, errs, nil
+}
+
+// ListRegistries returns the registries loaded by the parser
+func (c *Client) ListRegistries() ([]registry.Summary, error) {
+	if !c.Serving() {
+		return nil, ErrUnavailable
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	resp, err := c.parser.ListRegistries(ctx, &pb.ListRegistriesRequest{})
+	if err != nil {
+		st, _ := status.FromError(err)
+		return nil, statusError(st.Code(), st.Message(), "")
+	}
+	summaries := make([]registry.Summary, 0, len(resp.Registries))
+	for _, r := range resp.Registries {
+		summaries = append(summaries, registry.Summary{
+			RegistryPrefix:  r.RegistryPrefix,
+			RegistryVersion: r.RegistryVersion,
+			Language:        r.Language,
+			OwningEntity:    r.OwningEntity,
+			Messages:        int(r.MessageCount),
+		})
+	}
+	return summaries, nil
+}
+
+// parseEach parses the records one by one
+func (c *Client) parseEach(records []redfish.EventRecord) ([]registry.Resolved, []error) {
+	results := make([]registry.Resolved, len(records))
+	errs := make([]error, len(records))
+	for i, m := range records {
+		results[i], errs[i] = c.Parse(m)
+	}
+	return results, errs
+}
+
+// statusError maps a status code of the parser to the typed errors
+func statusError(code codes.Code, msg, messageID string) error {
+	if messageID == "" {
+		messageID = msg
+	}
+	switch code {
+	case codes.OK:
+		return nil
+	case codes.NotFound:
+		return fmt.Errorf("%w: %s", ErrUnknownMessage, messageID)
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: %s", ErrInvalidArgument, msg)
+	case codes.Unavailable:
+		return fmt.Errorf("%w: %s", ErrUnavailable, msg)
+	default:
+		return status.Error(code, msg)
+	}
+}
+
+// count counts the result of parsing one record and returns err
+func (c *Client) count(err error) error {
+	switch {
+	case err == nil:
+		c.parsed.Add(1)
+	case errors.Is(err, ErrUnknownMessage):
+		c.notFound.Add(1)
+	case errors.Is(err, ErrUnavailable):
+		c.unavailable.Add(1)
+	case errors.Is(err, ErrInvalidArgument):
+		c.invalidArgument.Add(1)
+	default:
+		c.failed.Add(1)
+	}
+	return err
+}
+
+// resolved converts a response of the parser
+func resolved(resp *pb.ParserResponse) registry.Resolved {
+	r := registry.Resolved{
+		Message:    resp.Message,
+		Severity:   resp.Severity,
+		Resolution: resp.Resolution,
+		Metadata: registry.Metadata{
+			RegistryPrefix:  resp.RegistryPrefix,
+			RegistryVersion: resp.RegistryVersion,
+			MessageKey:      resp.MessageKey,
+			NumberOfArgs:    int(resp.NumberOfArgs),
+			ParamTypes:      resp.ParamTypes,
+		},
+	}
+	if cl := resp.ClearingLogic; cl != nil {
+		r.ClearingLogic = &registry.ClearingLogic{
+			ClearsAll:     cl.ClearsAll,
+			ClearsIf:      cl.ClearsIf,
+			ClearsMessage: cl.ClearsMessage,
+		}
+	}
+	return r
+}
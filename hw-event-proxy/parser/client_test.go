@@ -0,0 +1,132 @@
+//go:build unittests
+// +build unittests
+
+package parser
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/redhat-cne/sdk-go/pkg/event/redfish"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/pb"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/registry"
+)
+
+type fakeParser struct {
+	pb.UnimplementedMessageParserServer
+}
+
+// fakeParser resolves TMP0100 like the message parser, a MessageId without registry
+// is looked up in all the registries
+func (fakeParser) Parse(_ context.Context, req *pb.ParserRequest) (*pb.ParserResponse, error) {
+	if req.MessageId != "TMP0100" && req.MessageId != "IDRAC.2.8.TMP0100" {
+		return nil, status.Errorf(codes.NotFound, "unable to find message %s in Redfish Registries", req.MessageId)
+	}
+	if len(req.MessageArgs) != 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "message %s takes 1 argument", req.MessageId)
+	}
+	return &pb.ParserResponse{Message: "The system board " + req.MessageArgs[0] + " temperature is less than the lower warning threshold.", Severity: "Warning",
+		RegistryPrefix: "IDRAC", RegistryVersion: "2.8.0", MessageKey: "TMP0100", NumberOfArgs: 1, ParamTypes: []string{"string"}}, nil
+}
+
+func (p fakeParser) ParseBatch(ctx context.Context, req *pb.ParseBatchRequest) (*pb.ParseBatchResponse, error) {
+	resp := &pb.ParseBatchResponse{}
+	for _, r := range req.Requests {
+		parsed, err := p.Parse(ctx, r)
+		if err != nil {
+			st := status.Convert(err)
+			resp.Results = append(resp.Results, &pb.ParseResult{Code: int32(st.Code()), Error: st.Message()})
+			continue
+		}
+		resp.Results = append(resp.Results, &pb.ParseResult{Response: parsed})
+	}
+	return resp, nil
+}
+
+func (fakeParser) ListRegistries(context.Context, *pb.ListRegistriesRequest) (*pb.ListRegistriesResponse, error) {
+	return &pb.ListRegistriesResponse{Registries: []*pb.RegistrySummary{
+		{RegistryPrefix: "IDRAC", RegistryVersion: "2.8.0", Language: "En", OwningEntity: "Dell", MessageCount: 1},
+	}}, nil
+}
+
+// fakeHealth sends the statuses received on its channel
+type fakeHealth struct {
+	healthpb.UnimplementedHealthServer
+	statuses chan healthpb.HealthCheckResponse_ServingStatus
+}
+
+func (h fakeHealth) Watch(_ *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	for s := range h.statuses {
+		if err := stream.Send(&healthpb.HealthCheckResponse{Status: s}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestClient(t *testing.T) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	server := grpc.NewServer()
+	pb.RegisterMessageParserServer(server, fakeParser{})
+	statuses := make(chan healthpb.HealthCheckResponse_ServingStatus)
+	healthpb.RegisterHealthServer(server, fakeHealth{statuses: statuses})
+	go server.Serve(lis) //nolint:errcheck
+	defer server.Stop()
+
+	c, err := NewClient(lis.Addr().String(), time.Second)
+	assert.NoError(t, err)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go c.Run(stopCh)
+
+	// not serving until the parser reports it
+	_, err = c.Parse(redfish.EventRecord{MessageID: "TMP0100", MessageArgs: []string{"Inlet"}})
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	statuses <- healthpb.HealthCheckResponse_SERVING
+	assert.Eventually(t, c.Serving, time.Second, 10*time.Millisecond)
+	m, err := c.Parse(redfish.EventRecord{MessageID: "TMP0100", MessageArgs: []string{"Inlet"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Warning", m.Severity)
+	assert.Equal(t, registry.Metadata{RegistryPrefix: "IDRAC", RegistryVersion: "2.8.0", MessageKey: "TMP0100",
+		NumberOfArgs: 1, ParamTypes: []string{"string"}}, m.Metadata)
+	m, err = c.Parse(redfish.EventRecord{MessageID: "IDRAC.2.8.TMP0100", MessageArgs: []string{"Inlet"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "TMP0100", m.MessageKey)
+	_, err = c.Parse(redfish.EventRecord{MessageID: "TMP0000"})
+	assert.ErrorIs(t, err, ErrUnknownMessage)
+	_, err = c.Parse(redfish.EventRecord{MessageID: "TMP0100"})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	records := []redfish.EventRecord{
+		{MemberID: "0", MessageID: "TMP0100", MessageArgs: []string{"Inlet"}},
+		{MemberID: "1", MessageID: "TMP0000"},
+		{MemberID: "2", MessageID: "TMP0100"},
+	}
+	results, errs, err := c.ParseBatch(records)
+	assert.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], ErrUnknownMessage)
+	assert.ErrorIs(t, errs[2], ErrInvalidArgument)
+	assert.Equal(t, "The system board Inlet temperature is less than the lower warning threshold.", results[0].Message)
+	assert.Equal(t, "TMP0100", results[0].MessageKey)
+	assert.Empty(t, results[1].Message)
+	summaries, err := c.ListRegistries()
+	assert.NoError(t, err)
+	assert.Equal(t, []registry.Summary{{RegistryPrefix: "IDRAC", RegistryVersion: "2.8.0", Language: "En", OwningEntity: "Dell", Messages: 1}}, summaries)
+	assert.Equal(t, Stats{Parsed: 3, NotFound: 2, Unavailable: 1, InvalidArgument: 2}, c.Stats())
+
+	statuses <- healthpb.HealthCheckResponse_NOT_SERVING
+	assert.Eventually(t, func() bool { return !c.Serving() }, time.Second, 10*time.Millisecond)
+	_, err = c.Parse(redfish.EventRecord{MessageID: "TMP0100", MessageArgs: []string{"Inlet"}})
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.26.0
+// 	protoc-gen-go v1.33.0
 // 	protoc        v3.17.3
 // source: message_parser.proto
 
@@ -84,9 +84,15 @@ type ParserResponse struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Message    string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
-	Severity   string `protobuf:"bytes,2,opt,name=severity,proto3" json:"severity,omitempty"`
-	Resolution string `protobuf:"bytes,3,opt,name=resolution,proto3" json:"resolution,omitempty"`
+	Message         string         `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Severity        string         `protobuf:"bytes,2,opt,name=severity,proto3" json:"severity,omitempty"`
+	Resolution      string         `protobuf:"bytes,3,opt,name=resolution,proto3" json:"resolution,omitempty"`
+	RegistryVersion string         `protobuf:"bytes,4,opt,name=registry_version,json=registryVersion,proto3" json:"registry_version,omitempty"`
+	RegistryPrefix  string         `protobuf:"bytes,5,opt,name=registry_prefix,json=registryPrefix,proto3" json:"registry_prefix,omitempty"`
+	MessageKey      string         `protobuf:"bytes,6,opt,name=message_key,json=messageKey,proto3" json:"message_key,omitempty"`
+	NumberOfArgs    int32          `protobuf:"varint,7,opt,name=number_of_args,json=numberOfArgs,proto3" json:"number_of_args,omitempty"`
+	ParamTypes      []string       `protobuf:"bytes,8,rep,name=param_types,json=paramTypes,proto3" json:"param_types,omitempty"`
+	ClearingLogic   *ClearingLogic `protobuf:"bytes,9,opt,name=clearing_logic,json=clearingLogic,proto3" json:"clearing_logic,omitempty"`
 }
 
 func (x *ParserResponse) Reset() {
@@ -142,6 +148,432 @@ func (x *ParserResponse) GetResolution() string {
 	return ""
 }
 
+func (x *ParserResponse) GetRegistryVersion() string {
+	if x != nil {
+		return x.RegistryVersion
+	}
+	return ""
+}
+
+func (x *ParserResponse) GetRegistryPrefix() string {
+	if x != nil {
+		return x.RegistryPrefix
+	}
+	return ""
+}
+
+func (x *ParserResponse) GetMessageKey() string {
+	if x != nil {
+		return x.MessageKey
+	}
+	return ""
+}
+
+func (x *ParserResponse) GetNumberOfArgs() int32 {
+	if x != nil {
+		return x.NumberOfArgs
+	}
+	return 0
+}
+
+func (x *ParserResponse) GetParamTypes() []string {
+	if x != nil {
+		return x.ParamTypes
+	}
+	return nil
+}
+
+func (x *ParserResponse) GetClearingLogic() *ClearingLogic {
+	if x != nil {
+		return x.ClearingLogic
+	}
+	return nil
+}
+
+type ClearingLogic struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ClearsAll     bool     `protobuf:"varint,1,opt,name=clears_all,json=clearsAll,proto3" json:"clears_all,omitempty"`
+	ClearsIf      string   `protobuf:"bytes,2,opt,name=clears_if,json=clearsIf,proto3" json:"clears_if,omitempty"`
+	ClearsMessage []string `protobuf:"bytes,3,rep,name=clears_message,json=clearsMessage,proto3" json:"clears_message,omitempty"`
+}
+
+func (x *ClearingLogic) Reset() {
+	*x = ClearingLogic{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_parser_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClearingLogic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearingLogic) ProtoMessage() {}
+
+func (x *ClearingLogic) ProtoReflect() protoreflect.Message {
+	mi := &file_message_parser_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearingLogic.ProtoReflect.Descriptor instead.
+func (*ClearingLogic) Descriptor() ([]byte, []int) {
+	return file_message_parser_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ClearingLogic) GetClearsAll() bool {
+	if x != nil {
+		return x.ClearsAll
+	}
+	return false
+}
+
+func (x *ClearingLogic) GetClearsIf() string {
+	if x != nil {
+		return x.ClearsIf
+	}
+	return ""
+}
+
+func (x *ClearingLogic) GetClearsMessage() []string {
+	if x != nil {
+		return x.ClearsMessage
+	}
+	return nil
+}
+
+type ParseBatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Requests []*ParserRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+}
+
+func (x *ParseBatchRequest) Reset() {
+	*x = ParseBatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_parser_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ParseBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseBatchRequest) ProtoMessage() {}
+
+func (x *ParseBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_parser_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseBatchRequest.ProtoReflect.Descriptor instead.
+func (*ParseBatchRequest) Descriptor() ([]byte, []int) {
+	return file_message_parser_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ParseBatchRequest) GetRequests() []*ParserRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+type ParseResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Response *ParserResponse `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	Error    string          `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Code     int32           `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+}
+
+func (x *ParseResult) Reset() {
+	*x = ParseResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_parser_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ParseResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseResult) ProtoMessage() {}
+
+func (x *ParseResult) ProtoReflect() protoreflect.Message {
+	mi := &file_message_parser_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseResult.ProtoReflect.Descriptor instead.
+func (*ParseResult) Descriptor() ([]byte, []int) {
+	return file_message_parser_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ParseResult) GetResponse() *ParserResponse {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *ParseResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ParseResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+type ParseBatchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results []*ParseResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *ParseBatchResponse) Reset() {
+	*x = ParseBatchResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_parser_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ParseBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseBatchResponse) ProtoMessage() {}
+
+func (x *ParseBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_parser_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseBatchResponse.ProtoReflect.Descriptor instead.
+func (*ParseBatchResponse) Descriptor() ([]byte, []int) {
+	return file_message_parser_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ParseBatchResponse) GetResults() []*ParseResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ListRegistriesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListRegistriesRequest) Reset() {
+	*x = ListRegistriesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_parser_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListRegistriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRegistriesRequest) ProtoMessage() {}
+
+func (x *ListRegistriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_parser_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRegistriesRequest.ProtoReflect.Descriptor instead.
+func (*ListRegistriesRequest) Descriptor() ([]byte, []int) {
+	return file_message_parser_proto_rawDescGZIP(), []int{6}
+}
+
+type RegistrySummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RegistryPrefix  string `protobuf:"bytes,1,opt,name=registry_prefix,json=registryPrefix,proto3" json:"registry_prefix,omitempty"`
+	RegistryVersion string `protobuf:"bytes,2,opt,name=registry_version,json=registryVersion,proto3" json:"registry_version,omitempty"`
+	Language        string `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	OwningEntity    string `protobuf:"bytes,4,opt,name=owning_entity,json=owningEntity,proto3" json:"owning_entity,omitempty"`
+	MessageCount    int32  `protobuf:"varint,5,opt,name=message_count,json=messageCount,proto3" json:"message_count,omitempty"`
+}
+
+func (x *RegistrySummary) Reset() {
+	*x = RegistrySummary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_parser_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegistrySummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistrySummary) ProtoMessage() {}
+
+func (x *RegistrySummary) ProtoReflect() protoreflect.Message {
+	mi := &file_message_parser_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistrySummary.ProtoReflect.Descriptor instead.
+func (*RegistrySummary) Descriptor() ([]byte, []int) {
+	return file_message_parser_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RegistrySummary) GetRegistryPrefix() string {
+	if x != nil {
+		return x.RegistryPrefix
+	}
+	return ""
+}
+
+func (x *RegistrySummary) GetRegistryVersion() string {
+	if x != nil {
+		return x.RegistryVersion
+	}
+	return ""
+}
+
+func (x *RegistrySummary) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *RegistrySummary) GetOwningEntity() string {
+	if x != nil {
+		return x.OwningEntity
+	}
+	return ""
+}
+
+func (x *RegistrySummary) GetMessageCount() int32 {
+	if x != nil {
+		return x.MessageCount
+	}
+	return 0
+}
+
+type ListRegistriesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Registries []*RegistrySummary `protobuf:"bytes,1,rep,name=registries,proto3" json:"registries,omitempty"`
+}
+
+func (x *ListRegistriesResponse) Reset() {
+	*x = ListRegistriesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_parser_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListRegistriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRegistriesResponse) ProtoMessage() {}
+
+func (x *ListRegistriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_parser_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRegistriesResponse.ProtoReflect.Descriptor instead.
+func (*ListRegistriesResponse) Descriptor() ([]byte, []int) {
+	return file_message_parser_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListRegistriesResponse) GetRegistries() []*RegistrySummary {
+	if x != nil {
+		return x.Registries
+	}
+	return nil
+}
+
 var File_message_parser_proto protoreflect.FileDescriptor
 
 var file_message_parser_proto_rawDesc = []byte{
@@ -151,22 +583,88 @@ var file_message_parser_proto_rawDesc = []byte{
 	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
 	0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65,
 	0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x61, 0x72, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
-	0x52, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x41, 0x72, 0x67, 0x73, 0x22, 0x66, 0x0a,
-	0x0e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x76,
-	0x65, 0x72, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x76,
-	0x65, 0x72, 0x69, 0x74, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74,
-	0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x6f, 0x6c,
-	0x75, 0x74, 0x69, 0x6f, 0x6e, 0x32, 0x41, 0x0a, 0x0d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x52, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x41, 0x72, 0x67, 0x73, 0x22, 0xdc, 0x02,
+	0x0a, 0x0e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65,
+	0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65,
+	0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x6f,
+	0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74,
+	0x72, 0x79, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0f, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x27, 0x0a, 0x0f, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x5f, 0x70, 0x72,
+	0x65, 0x66, 0x69, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x72, 0x65, 0x67, 0x69,
+	0x73, 0x74, 0x72, 0x79, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0a, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4b, 0x65, 0x79, 0x12, 0x24, 0x0a, 0x0e, 0x6e,
+	0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x6f, 0x66, 0x5f, 0x61, 0x72, 0x67, 0x73, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0c, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x4f, 0x66, 0x41, 0x72, 0x67,
+	0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x73,
+	0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x54, 0x79, 0x70,
+	0x65, 0x73, 0x12, 0x38, 0x0a, 0x0e, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x6c,
+	0x6f, 0x67, 0x69, 0x63, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x62, 0x2e,
+	0x43, 0x6c, 0x65, 0x61, 0x72, 0x69, 0x6e, 0x67, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x52, 0x0d, 0x63,
+	0x6c, 0x65, 0x61, 0x72, 0x69, 0x6e, 0x67, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x22, 0x72, 0x0a, 0x0d,
+	0x43, 0x6c, 0x65, 0x61, 0x72, 0x69, 0x6e, 0x67, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x12, 0x1d, 0x0a,
+	0x0a, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x73, 0x5f, 0x61, 0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x73, 0x41, 0x6c, 0x6c, 0x12, 0x1b, 0x0a, 0x09,
+	0x63, 0x6c, 0x65, 0x61, 0x72, 0x73, 0x5f, 0x69, 0x66, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x73, 0x49, 0x66, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6c, 0x65,
+	0x61, 0x72, 0x73, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0d, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x73, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x22, 0x42, 0x0a, 0x11, 0x50, 0x61, 0x72, 0x73, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2d, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x61, 0x72,
+	0x73, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x73, 0x22, 0x67, 0x0a, 0x0b, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x12, 0x2e, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65,
+	0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x22, 0x3f, 0x0a,
+	0x12, 0x50, 0x61, 0x72, 0x73, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22, 0x17,
+	0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x69, 0x65, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xcb, 0x01, 0x0a, 0x0f, 0x52, 0x65, 0x67, 0x69,
+	0x73, 0x74, 0x72, 0x79, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x27, 0x0a, 0x0f, 0x72,
+	0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x50, 0x72,
+	0x65, 0x66, 0x69, 0x78, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79,
+	0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f,
+	0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x6f,
+	0x77, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x6f, 0x77, 0x6e, 0x69, 0x6e, 0x67, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79,
+	0x12, 0x23, 0x0a, 0x0d, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x4d, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x67,
+	0x69, 0x73, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x33, 0x0a, 0x0a, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72,
+	0x79, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x52, 0x0a, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x32, 0xcb, 0x01, 0x0a, 0x0d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
 	0x50, 0x61, 0x72, 0x73, 0x65, 0x72, 0x12, 0x30, 0x0a, 0x05, 0x50, 0x61, 0x72, 0x73, 0x65, 0x12,
 	0x11, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
 	0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x72, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x3d, 0x5a, 0x3b, 0x67, 0x69, 0x74, 0x68,
-	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x65, 0x64, 0x68, 0x61, 0x74, 0x2d, 0x63, 0x6e,
-	0x65, 0x2f, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x2d, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2d, 0x70, 0x72,
-	0x6f, 0x78, 0x79, 0x2f, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2f, 0x68, 0x77, 0x5f, 0x65,
-	0x76, 0x65, 0x6e, 0x74, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x3d, 0x0a, 0x0a, 0x50, 0x61, 0x72, 0x73,
+	0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x61, 0x72, 0x73,
+	0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e,
+	0x70, 0x62, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x49, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74, 0x52,
+	0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x19, 0x2e, 0x70, 0x62, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65,
+	0x67, 0x69, 0x73, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x42, 0x3d, 0x5a, 0x3b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x72, 0x65, 0x64, 0x68, 0x61, 0x74, 0x2d, 0x63, 0x6e, 0x65, 0x2f, 0x63, 0x6c, 0x6f, 0x75,
+	0x64, 0x2d, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2d, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x2f, 0x70, 0x6c,
+	0x75, 0x67, 0x69, 0x6e, 0x73, 0x2f, 0x68, 0x77, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2f, 0x70,
+	0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -181,19 +679,35 @@ func file_message_parser_proto_rawDescGZIP() []byte {
 	return file_message_parser_proto_rawDescData
 }
 
-var file_message_parser_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_message_parser_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_message_parser_proto_goTypes = []interface{}{
-	(*ParserRequest)(nil),  // 0: pb.ParserRequest
-	(*ParserResponse)(nil), // 1: pb.ParserResponse
+	(*ParserRequest)(nil),          // 0: pb.ParserRequest
+	(*ParserResponse)(nil),         // 1: pb.ParserResponse
+	(*ClearingLogic)(nil),          // 2: pb.ClearingLogic
+	(*ParseBatchRequest)(nil),      // 3: pb.ParseBatchRequest
+	(*ParseResult)(nil),            // 4: pb.ParseResult
+	(*ParseBatchResponse)(nil),     // 5: pb.ParseBatchResponse
+	(*ListRegistriesRequest)(nil),  // 6: pb.ListRegistriesRequest
+	(*RegistrySummary)(nil),        // 7: pb.RegistrySummary
+	(*ListRegistriesResponse)(nil), // 8: pb.ListRegistriesResponse
 }
 var file_message_parser_proto_depIdxs = []int32{
-	0, // 0: pb.MessageParser.Parse:input_type -> pb.ParserRequest
-	1, // 1: pb.MessageParser.Parse:output_type -> pb.ParserResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2, // 0: pb.ParserResponse.clearing_logic:type_name -> pb.ClearingLogic
+	0, // 1: pb.ParseBatchRequest.requests:type_name -> pb.ParserRequest
+	1, // 2: pb.ParseResult.response:type_name -> pb.ParserResponse
+	4, // 3: pb.ParseBatchResponse.results:type_name -> pb.ParseResult
+	7, // 4: pb.ListRegistriesResponse.registries:type_name -> pb.RegistrySummary
+	0, // 5: pb.MessageParser.Parse:input_type -> pb.ParserRequest
+	3, // 6: pb.MessageParser.ParseBatch:input_type -> pb.ParseBatchRequest
+	6, // 7: pb.MessageParser.ListRegistries:input_type -> pb.ListRegistriesRequest
+	1, // 8: pb.MessageParser.Parse:output_type -> pb.ParserResponse
+	5, // 9: pb.MessageParser.ParseBatch:output_type -> pb.ParseBatchResponse
+	8, // 10: pb.MessageParser.ListRegistries:output_type -> pb.ListRegistriesResponse
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_message_parser_proto_init() }
@@ -226,6 +740,90 @@ func file_message_parser_proto_init() {
 				return nil
 			}
 		}
+		file_message_parser_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClearingLogic); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_parser_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ParseBatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_parser_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ParseResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_parser_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ParseBatchResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_parser_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListRegistriesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_parser_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegistrySummary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_parser_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListRegistriesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -233,7 +831,7 @@ func file_message_parser_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_message_parser_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -260,6 +858,8 @@ const _ = grpc.SupportPackageIsVersion6
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type MessageParserClient interface {
 	Parse(ctx context.Context, in *ParserRequest, opts ...grpc.CallOption) (*ParserResponse, error)
+	ParseBatch(ctx context.Context, in *ParseBatchRequest, opts ...grpc.CallOption) (*ParseBatchResponse, error)
+	ListRegistries(ctx context.Context, in *ListRegistriesRequest, opts ...grpc.CallOption) (*ListRegistriesResponse, error)
 }
 
 type messageParserClient struct {
@@ -279,9 +879,29 @@ func (c *messageParserClient) Parse(ctx context.Context, in *ParserRequest, opts
 	return out, nil
 }
 
+func (c *messageParserClient) ParseBatch(ctx context.Context, in *ParseBatchRequest, opts ...grpc.CallOption) (*ParseBatchResponse, error) {
+	out := new(ParseBatchResponse)
+	err := c.cc.Invoke(ctx, "/pb.MessageParser/ParseBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageParserClient) ListRegistries(ctx context.Context, in *ListRegistriesRequest, opts ...grpc.CallOption) (*ListRegistriesResponse, error) {
+	out := new(ListRegistriesResponse)
+	err := c.cc.Invoke(ctx, "/pb.MessageParser/ListRegistries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MessageParserServer is the server API for MessageParser service.
 type MessageParserServer interface {
 	Parse(context.Context, *ParserRequest) (*ParserResponse, error)
+	ParseBatch(context.Context, *ParseBatchRequest) (*ParseBatchResponse, error)
+	ListRegistries(context.Context, *ListRegistriesRequest) (*ListRegistriesResponse, error)
 }
 
 // UnimplementedMessageParserServer can be embedded to have forward compatible implementations.
@@ -291,6 +911,12 @@ type UnimplementedMessageParserServer struct {
 func (*UnimplementedMessageParserServer) Parse(context.Context, *ParserRequest) (*ParserResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Parse not implemented")
 }
+func (*UnimplementedMessageParserServer) ParseBatch(context.Context, *ParseBatchRequest) (*ParseBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ParseBatch not implemented")
+}
+func (*UnimplementedMessageParserServer) ListRegistries(context.Context, *ListRegistriesRequest) (*ListRegistriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRegistries not implemented")
+}
 
 func RegisterMessageParserServer(s *grpc.Server, srv MessageParserServer) {
 	s.RegisterService(&_MessageParser_serviceDesc, srv)
@@ -314,6 +940,42 @@ func _MessageParser_Parse_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MessageParser_ParseBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParseBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageParserServer).ParseBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.MessageParser/ParseBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageParserServer).ParseBatch(ctx, req.(*ParseBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageParser_ListRegistries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRegistriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageParserServer).ListRegistries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.MessageParser/ListRegistries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageParserServer).ListRegistries(ctx, req.(*ListRegistriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _MessageParser_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.MessageParser",
 	HandlerType: (*MessageParserServer)(nil),
@@ -322,6 +984,14 @@ var _MessageParser_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Parse",
 			Handler:    _MessageParser_Parse_Handler,
 		},
+		{
+			MethodName: "ParseBatch",
+			Handler:    _MessageParser_ParseBatch_Handler,
+		},
+		{
+			MethodName: "ListRegistries",
+			Handler:    _MessageParser_ListRegistries_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "message_parser.proto",
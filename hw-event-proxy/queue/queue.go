@@ -0,0 +1,163 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// OverflowPolicy defines what happens when a task is added to a full queue
+type OverflowPolicy string
+
+const (
+	// DropOldest removes the oldest queued task to make room for the new one
+	DropOldest OverflowPolicy = "drop-oldest"
+	// DropNewest discards the new task
+	DropNewest OverflowPolicy = "drop-newest"
+	// Reject discards the new task and returns ErrQueueFull to the caller
+	Reject OverflowPolicy = "reject"
+)
+
+// ErrQueueFull is returned by Enqueue when the queue is full and the policy is Reject
+var ErrQueueFull = errors.New("queue is full")
+
+// Task is a unit of work processed by the queue workers, ctx is canceled when the queue is stopped
+type Task func(ctx context.Context)
+
+// Stats is a snapshot of the queue counters
+type Stats struct {
+	Depth         int    `json:"depth"`
+	Capacity      int    `json:"capacity"`
+	Workers       int    `json:"workers"`
+	Policy        string `json:"overflowPolicy"`
+	Enqueued      uint64 `json:"enqueued"`
+	Processed     uint64 `json:"processed"`
+	DroppedOldest uint64 `json:"droppedOldest"`
+	DroppedNewest uint64 `json:"droppedNewest"`
+	Rejected      uint64 `json:"rejected"`
+}
+
+// Queue is a bounded in-memory task queue served by a pool of workers
+type Queue struct {
+	tasks   chan Task
+	workers int
+	policy  OverflowPolicy
+	// mu serializes producers so that a drop-oldest is followed by the matching enqueue
+	mu            sync.Mutex
+	enqueued      uint64
+	processed     uint64
+	droppedOldest uint64
+	droppedNewest uint64
+	rejected      uint64
+}
+
+// ParseOverflowPolicy converts a string to an OverflowPolicy
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch p := OverflowPolicy(s); p {
+	case DropOldest, DropNewest, Reject:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown overflow policy %q, must be one of %s, %s or %s", s, DropOldest, DropNewest, Reject)
+}
+
+// New creates a queue holding up to size tasks processed by the given number of workers
+func New(size, workers int, policy OverflowPolicy) *Queue {
+	if size < 1 {
+		size = 1
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return &Queue{
+		tasks:   make(chan Task, size),
+		workers: workers,
+		policy:  policy,
+	}
+}
+
+// Start starts the workers, which run until stopCh is closed
+func (q *Queue) Start(stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+	for i := 0; i < q.workers; i++ {
+		go func() {
+			for {
+				select {
+				case t := <-q.tasks:
+					t(ctx)
+					atomic.AddUint64(&q.processed, 1)
+				case <-stopCh:
+					return
+				}
+			}
+		}()
+	}
+}
+
+// Enqueue adds a task to the queue, applying the overflow policy if the queue is full
+func (q *Queue) Enqueue(t Task) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		select {
+		case q.tasks <- t:
+			atomic.AddUint64(&q.enqueued, 1)
+			return nil
+		default:
+		}
+
+		switch q.policy {
+		case DropOldest:
+			select {
+			case <-q.tasks:
+				atomic.AddUint64(&q.droppedOldest, 1)
+				log.Warnf("event queue is full (%d), dropped the oldest event", cap(q.tasks))
+			default:
+				// a worker freed a slot in the meantime
+			}
+		case DropNewest:
+			atomic.AddUint64(&q.droppedNewest, 1)
+			log.Warnf("event queue is full (%d), dropped the newest event", cap(q.tasks))
+			return nil
+		default:
+			atomic.AddUint64(&q.rejected, 1)
+			return ErrQueueFull
+		}
+	}
+}
+
+// Stats returns a snapshot of the queue counters
+func (q *Queue) Stats() Stats {
+	return Stats{
+		Depth:         len(q.tasks),
+		Capacity:      cap(q.tasks),
+		Workers:       q.workers,
+		Policy:        string(q.policy),
+		Enqueued:      atomic.LoadUint64(&q.enqueued),
+		Processed:     atomic.LoadUint64(&q.processed),
+		DroppedOldest: atomic.LoadUint64(&q.droppedOldest),
+		DroppedNewest: atomic.LoadUint64(&q.droppedNewest),
+		Rejected:      atomic.LoadUint64(&q.rejected),
+	}
+}
@@ -0,0 +1,60 @@
+//go:build unittests
+// +build unittests
+
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fill(q *Queue, n int, out *[]int) {
+	for i := 0; i < n; i++ {
+		v := i
+		q.Enqueue(func(context.Context) { *out = append(*out, v) }) //nolint:errcheck
+	}
+}
+
+func drain(q *Queue) {
+	for len(q.tasks) > 0 {
+		(<-q.tasks)(context.Background())
+	}
+}
+
+func TestQueueReject(t *testing.T) {
+	q := New(2, 1, Reject)
+	var got []int
+	fill(q, 2, &got)
+	assert.ErrorIs(t, q.Enqueue(func(context.Context) {}), ErrQueueFull)
+	drain(q)
+	assert.Equal(t, []int{0, 1}, got)
+	assert.Equal(t, uint64(1), q.Stats().Rejected)
+}
+
+func TestQueueDropOldest(t *testing.T) {
+	q := New(2, 1, DropOldest)
+	var got []int
+	fill(q, 4, &got)
+	drain(q)
+	assert.Equal(t, []int{2, 3}, got)
+	assert.Equal(t, uint64(2), q.Stats().DroppedOldest)
+}
+
+func TestQueueDropNewest(t *testing.T) {
+	q := New(2, 1, DropNewest)
+	var got []int
+	fill(q, 4, &got)
+	drain(q)
+	assert.Equal(t, []int{0, 1}, got)
+	assert.Equal(t, uint64(2), q.Stats().DroppedNewest)
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	p, err := ParseOverflowPolicy("drop-oldest")
+	assert.NoError(t, err)
+	assert.Equal(t, DropOldest, p)
+	_, err = ParseOverflowPolicy("drop-all")
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxRegistrySize is the maximum size of a registry file read from a tarball
+const maxRegistrySize = 16 * 1048576
+
+// Load adds the registries of a bundle and returns the number loaded. The bundle is a directory,
+// e.g. mounted from a ConfigMap, a tar or tar.gz archive, or a single JSON file.
+// Files that are not message registries are skipped.
+func (rs *Resolver) Load(path string) (int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if info.IsDir() {
+		return rs.LoadDir(path)
+	}
+	if strings.HasSuffix(path, ".json") {
+		if err = rs.LoadFile(path); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+	return rs.LoadTar(path)
+}
+
+// LoadDir adds the registries of the JSON files of a directory and its subdirectories,
+// hidden directories such as the ..data directory of ConfigMap volumes are skipped
+func (rs *Resolver) LoadDir(dir string) (int, error) {
+	count := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		if loadErr := rs.LoadFile(path); loadErr != nil {
+			log.Warnf("skipped %s: %v", path, loadErr)
+			return nil
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// LoadTar adds the registries of the JSON files of a tar archive, compressed with gzip or not
+func (rs *Resolver) LoadTar(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	var r io.Reader = bufio.NewReader(f)
+	if magic, _ := r.(*bufio.Reader).Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, gzErr := gzip.NewReader(r)
+		if gzErr != nil {
+			return 0, gzErr
+		}
+		defer gz.Close()
+		r = gz
+	}
+	count := 0
+	tr := tar.NewReader(r)
+	for {
+		header, nextErr := tr.Next()
+		if nextErr == io.EOF {
+			return count, nil
+		}
+		if nextErr != nil {
+			return count, nextErr
+		}
+		if header.Typeflag != tar.TypeReg || !strings.HasSuffix(header.Name, ".json") {
+			continue
+		}
+		b, readErr := io.ReadAll(io.LimitReader(tr, maxRegistrySize))
+		if readErr != nil {
+			return count, readErr
+		}
+		reg, parseErr := Parse(b)
+		if parseErr != nil {
+			log.Warnf("skipped %s of %s: %v", header.Name, path, parseErr)
+			continue
+		}
+		rs.Add(reg)
+		count++
+	}
+}
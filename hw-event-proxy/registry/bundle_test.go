@@ -0,0 +1,56 @@
+//go:build unittests
+// +build unittests
+
+package registry
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registries.tar.gz")
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{"Base.1.8.1.json", "IDRAC.2.8.json", "Base.json"} {
+		b, readErr := os.ReadFile(filepath.Join("testdata", name))
+		assert.NoError(t, readErr)
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "registries/" + name, Mode: 0600, Size: int64(len(b))}))
+		_, err = tw.Write(b)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+	assert.NoError(t, f.Close())
+
+	rs := NewResolver()
+	count, err := rs.Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	_, err = rs.Resolve("TMP0100", []string{"Inlet"})
+	assert.NoError(t, err)
+}
+
+func TestLoadConfigMapDir(t *testing.T) {
+	// ConfigMap volumes link the files to a hidden timestamped directory
+	dir := t.TempDir()
+	data := filepath.Join(dir, "..2021_07_13_15_07_59.123")
+	assert.NoError(t, os.Mkdir(data, 0700))
+	b, err := os.ReadFile(filepath.Join("testdata", "IDRAC.2.8.json"))
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(data, "IDRAC.2.8.json"), b, 0600))
+	assert.NoError(t, os.Symlink(filepath.Base(data), filepath.Join(dir, "..data")))
+	assert.NoError(t, os.Symlink(filepath.Join("..data", "IDRAC.2.8.json"), filepath.Join(dir, "IDRAC.2.8.json")))
+
+	rs := NewResolver()
+	count, err := rs.Load(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// indexFile lists the cached registries of a BMC
+const indexFile = "index.json"
+
+// Cache persists the registries downloaded from a BMC, so that they are
+// available at startup before the BMC is reachable
+type Cache struct {
+	bmcID string
+	dir   string
+}
+
+// cacheIndex identifies the BMC the registries were downloaded from and their versions
+type cacheIndex struct {
+	// Identity is the UUID of the Redfish service of the BMC
+	Identity string `json:"identity"`
+	// Versions are the registry versions keyed by registry prefix
+	Versions map[string]string `json:"versions"`
+	Updated  time.Time         `json:"updated"`
+}
+
+// NewCache creates the cache of a BMC in <storePath>/registries/<bmcID>
+func NewCache(storePath, bmcID string) *Cache {
+	return &Cache{bmcID: bmcID, dir: filepath.Join(storePath, "registries", bmcID)}
+}
+
+// Load returns the cached registries and the identity of the BMC they were downloaded from,
+// an empty identity is returned when nothing is cached
+func (c *Cache) Load() (identity string, registries []*Registry, err error) {
+	index, err := c.index()
+	if err != nil || index.Identity == "" {
+		return "", nil, err
+	}
+	for prefix, version := range index.Versions {
+		path := filepath.Join(c.dir, fileName(prefix, version))
+		b, readErr := os.ReadFile(path)
+		if readErr != nil {
+			log.Warnf("skipped cached registry %s: %v", path, readErr)
+			continue
+		}
+		r, parseErr := Parse(b)
+		if parseErr != nil {
+			log.Warnf("skipped cached registry %s: %v", path, parseErr)
+			continue
+		}
+		registries = append(registries, r)
+	}
+	return index.Identity, registries, nil
+}
+
+// Save replaces the cached registries with the registries downloaded from the BMC and logs the
+// registries that were added, removed or changed version since the last download
+func (c *Cache) Save(identity string, registries []*Registry) error {
+	previous, err := c.index()
+	if err != nil {
+		log.Warnf("failed to read the registry cache index of BMC %s: %v", c.bmcID, err)
+	}
+	index := cacheIndex{Identity: identity, Versions: map[string]string{}, Updated: time.Now().UTC()}
+	for _, r := range registries {
+		// keep the newest version when the BMC hosts several
+		if v, ok := index.Versions[r.RegistryPrefix]; ok && compareVersions(mustParseVersion(v), r.version) > 0 {
+			continue
+		}
+		index.Versions[r.RegistryPrefix] = r.RegistryVersion
+	}
+	if previous.Identity != "" && previous.Identity != identity {
+		log.Infof("BMC %s identity changed from %s to %s, replacing its cached registries", c.bmcID, previous.Identity, identity)
+	} else if previous.Identity != "" {
+		c.logChanges(previous.Versions, index.Versions)
+	}
+
+	if err = os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	for _, r := range registries {
+		if index.Versions[r.RegistryPrefix] != r.RegistryVersion {
+			continue
+		}
+		b, marshalErr := json.Marshal(r)
+		if marshalErr != nil {
+			return marshalErr
+		}
+		if err = writeFile(filepath.Join(c.dir, fileName(r.RegistryPrefix, r.RegistryVersion)), b); err != nil {
+			return err
+		}
+	}
+	b, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err = writeFile(filepath.Join(c.dir, indexFile), b); err != nil {
+		return err
+	}
+	c.prune(index)
+	return nil
+}
+
+// logChanges logs the differences between the previous and current registry versions
+func (c *Cache) logChanges(previous, current map[string]string) {
+	prefixes := make([]string, 0, len(previous)+len(current))
+	for p := range previous {
+		prefixes = append(prefixes, p)
+	}
+	for p := range current {
+		if _, ok := previous[p]; !ok {
+			prefixes = append(prefixes, p)
+		}
+	}
+	sort.Strings(prefixes)
+	for _, p := range prefixes {
+		before, after := previous[p], current[p]
+		switch {
+		case before == "":
+			log.Infof("BMC %s added registry %s %s", c.bmcID, p, after)
+		case after == "":
+			log.Infof("BMC %s removed registry %s %s", c.bmcID, p, before)
+		case before != after:
+			log.Infof("BMC %s changed registry %s from %s to %s", c.bmcID, p, before, after)
+		}
+	}
+}
+
+// prune removes the cached registries not in the index
+func (c *Cache) prune(index cacheIndex) {
+	keep := map[string]bool{indexFile: true}
+	for prefix, version := range index.Versions {
+		keep[fileName(prefix, version)] = true
+	}
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if !keep[e.Name()] && strings.HasSuffix(e.Name(), ".json") {
+			if err = os.Remove(filepath.Join(c.dir, e.Name())); err != nil {
+				log.Warnf("failed to remove cached registry %s: %v", e.Name(), err)
+			}
+		}
+	}
+}
+
+func (c *Cache) index() (cacheIndex, error) {
+	index := cacheIndex{}
+	b, err := os.ReadFile(filepath.Join(c.dir, indexFile))
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return index, err
+	}
+	if err = json.Unmarshal(b, &index); err != nil {
+		return cacheIndex{}, fmt.Errorf("failed to unmarshal %s: %v", indexFile, err)
+	}
+	return index, nil
+}
+
+// fileName is the name of the cached file of a registry, e.g. Base.1.8.1.json
+func fileName(prefix, version string) string {
+	return fmt.Sprintf("%s.%s.json", prefix, version)
+}
+
+func mustParseVersion(s string) []int {
+	v, _ := parseVersion(strings.Split(s, "."))
+	return v
+}
+
+// writeFile replaces a file atomically
+func writeFile(path string, b []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,50 @@
+//go:build unittests
+// +build unittests
+
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	store := t.TempDir()
+	c := NewCache(store, "bmc-1")
+	identity, registries, err := c.Load()
+	assert.NoError(t, err)
+	assert.Empty(t, identity)
+	assert.Empty(t, registries)
+
+	rs := NewResolver()
+	_, err = rs.LoadDir("testdata")
+	assert.NoError(t, err)
+	base14, base18, idrac := rs.registries["Base"][1], rs.registries["Base"][0], rs.registries["IDRAC"][0]
+	assert.NoError(t, c.Save("uuid-1", []*Registry{base14, idrac}))
+
+	// a restart serves the registries from the cache
+	identity, registries, err = NewCache(store, "bmc-1").Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "uuid-1", identity)
+	assert.Len(t, registries, 2)
+	cached := NewResolver()
+	for _, r := range registries {
+		cached.Add(r)
+	}
+	m, err := cached.Resolve("IDRAC.2.8.TMP0100", []string{"Inlet"})
+	assert.NoError(t, err)
+	assert.Equal(t, "The system board Inlet temperature is less than the lower warning threshold.", m.Message)
+
+	// a firmware update changed the Base registry version, the old version is removed
+	assert.NoError(t, c.Save("uuid-1", []*Registry{base14, base18, idrac}))
+	_, registries, err = c.Load()
+	assert.NoError(t, err)
+	assert.Len(t, registries, 2)
+	_, err = os.Stat(filepath.Join(store, "registries", "bmc-1", "Base.1.4.0.json"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(store, "registries", "bmc-1", "Base.1.8.1.json"))
+	assert.NoError(t, err)
+}
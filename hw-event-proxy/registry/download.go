@@ -0,0 +1,129 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/bmc"
+)
+
+const (
+	serviceRootPath = "/redfish/v1"
+	registriesPath  = "/redfish/v1/Registries"
+	requestTimeout  = 30 * time.Second
+)
+
+// registryFile is a Redfish MessageRegistryFile, the location of a registry
+type registryFile struct {
+	ID       string             `json:"Id"`
+	Registry string             `json:"Registry"`
+	Location []registryLocation `json:"Location"`
+}
+
+type registryLocation struct {
+	Language string `json:"Language"`
+	// URI is the location of the registry on the BMC,
+	// the PublicationUri at DMTF or the vendor is not used
+	URI string `json:"Uri"`
+}
+
+// collection is the registry files collection
+type collection struct {
+	Members []struct {
+		ID string `json:"@odata.id"`
+	} `json:"Members"`
+}
+
+// Downloader downloads the message registries hosted by a BMC
+type Downloader struct {
+	conn       *bmc.Connection
+	httpClient *http.Client
+}
+
+// NewDownloader creates a downloader for the BMC
+func NewDownloader(conn *bmc.Connection) *Downloader {
+	return &Downloader{conn: conn, httpClient: conn.HTTPClient(requestTimeout)}
+}
+
+// Identity returns the UUID of the Redfish service of the BMC, or its address
+// for services without UUID
+func (d *Downloader) Identity(ctx context.Context) (string, error) {
+	var root struct {
+		UUID string `json:"UUID"`
+	}
+	if err := d.conn.Get(ctx, d.httpClient, serviceRootPath, &root); err != nil {
+		return "", err
+	}
+	if root.UUID == "" {
+		return d.conn.Address, nil
+	}
+	return root.UUID, nil
+}
+
+// Download returns the message registries listed in /redfish/v1/Registries that the BMC hosts.
+// Registries that fail to download are skipped, an error is returned if the BMC is not reachable.
+func (d *Downloader) Download(ctx context.Context) ([]*Registry, error) {
+	files := collection{}
+	if err := d.conn.Get(ctx, d.httpClient, registriesPath, &files); err != nil {
+		return nil, err
+	}
+	var registries []*Registry
+	for _, m := range files.Members {
+		r, err := d.download(ctx, m.ID)
+		if err != nil {
+			log.Warnf("skipped registry %s of BMC %s: %v", m.ID, d.conn.Address, err)
+			continue
+		}
+		if r != nil {
+			registries = append(registries, r)
+		}
+	}
+	return registries, nil
+}
+
+// download reads a registry file and the English registry it links to,
+// nil is returned for registries not hosted by the BMC
+func (d *Downloader) download(ctx context.Context, path string) (*Registry, error) {
+	file := registryFile{}
+	if err := d.conn.Get(ctx, d.httpClient, path, &file); err != nil {
+		return nil, err
+	}
+	uri := ""
+	for _, l := range file.Location {
+		if l.URI != "" && (uri == "" || isEnglish(l.Language)) {
+			uri = l.URI
+		}
+	}
+	if uri == "" {
+		log.Debugf("registry %s is not hosted by BMC %s", file.Registry, d.conn.Address)
+		return nil, nil
+	}
+	var raw jsoniter.RawMessage
+	if err := d.conn.Get(ctx, d.httpClient, uri, &raw); err != nil {
+		return nil, err
+	}
+	r, err := Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", uri, err)
+	}
+	return r, nil
+}
@@ -0,0 +1,50 @@
+//go:build unittests
+// +build unittests
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/bmc"
+)
+
+func TestDownload(t *testing.T) {
+	idrac, err := os.ReadFile("testdata/IDRAC.2.8.json")
+	assert.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc(registriesPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Members": [{"@odata.id": "/redfish/v1/Registries/IDRAC.2.8"}, {"@odata.id": "/redfish/v1/Registries/Base.1.8"}]}`)
+	})
+	mux.HandleFunc(registriesPath+"/IDRAC.2.8", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Id": "IDRAC.2.8", "Registry": "IDRAC.2.8", "Location": [
+			{"Language": "ja", "Uri": "/registries/IDRAC.2.8.ja.json"},
+			{"Language": "en", "Uri": "/registries/IDRAC.2.8.json"}]}`)
+	})
+	mux.HandleFunc("/registries/IDRAC.2.8.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(idrac) //nolint:errcheck
+	})
+	// registries published by DMTF are not hosted by the BMC
+	mux.HandleFunc(registriesPath+"/Base.1.8", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Id": "Base.1.8", "Registry": "Base.1.8", "Location": [
+			{"Language": "en", "PublicationUri": "https://redfish.dmtf.org/registries/Base.1.8.1.json"}]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	registries, err := NewDownloader(&bmc.Connection{Address: server.URL}).Download(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, registries, 1)
+	assert.Equal(t, "IDRAC", registries[0].RegistryPrefix)
+
+	server.Close()
+	_, err = NewDownloader(&bmc.Connection{Address: server.URL}).Download(context.Background())
+	assert.Error(t, err)
+}
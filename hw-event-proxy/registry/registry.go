@@ -0,0 +1,326 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package registry resolves the MessageId of Redfish event records
+// with DMTF and OEM message registries.
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// registryOdataType is the prefix of the @odata.type of message registries
+const registryOdataType = "#MessageRegistry."
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// ErrUnknownMessage is returned for MessageIds not found in the registries
+var ErrUnknownMessage = errors.New("unable to find message in Redfish Registries")
+
+// argRegexp matches the %1..%n placeholders of a message
+var argRegexp = regexp.MustCompile(`%(\d+)`)
+
+// Registry is a Redfish MessageRegistry
+// https://redfish.dmtf.org/schemas/v1/MessageRegistry.v1_5_0.json
+type Registry struct {
+	OdataType       string             `json:"@odata.type"`
+	ID              string             `json:"Id"`
+	Language        string             `json:"Language"`
+	OwningEntity    string             `json:"OwningEntity"`
+	RegistryPrefix  string             `json:"RegistryPrefix"`
+	RegistryVersion string             `json:"RegistryVersion"`
+	Messages        map[string]Message `json:"Messages"`
+
+	version []int
+}
+
+// Message is a message of a registry
+type Message struct {
+	Description string `json:"Description"`
+	// Message is the text of the message with %1..%n argument placeholders
+	Message string `json:"Message"`
+	// Severity is deprecated in favor of MessageSeverity
+	Severity        string         `json:"Severity"`
+	MessageSeverity string         `json:"MessageSeverity"`
+	NumberOfArgs    int            `json:"NumberOfArgs"`
+	ParamTypes      []string       `json:"ParamTypes"`
+	Resolution      string         `json:"Resolution"`
+	ClearingLogic   *ClearingLogic `json:"ClearingLogic,omitempty"`
+}
+
+// ClearingLogic tells which messages a message clears
+type ClearingLogic struct {
+	// ClearsAll is true if the message clears all the messages of the resource
+	ClearsAll bool `json:"ClearsAll,omitempty"`
+	// ClearsIf is the condition for clearing, e.g. SameOriginOfCondition
+	ClearsIf string `json:"ClearsIf,omitempty"`
+	// ClearsMessage are the MessageIds cleared, the registry prefix and version are omitted
+	ClearsMessage []string `json:"ClearsMessage,omitempty"`
+}
+
+// Metadata identifies the registry message a MessageId was resolved with
+type Metadata struct {
+	RegistryPrefix string `json:"RegistryPrefix"`
+	// RegistryVersion may differ from the MessageId when its version was not loaded
+	RegistryVersion string         `json:"RegistryVersion"`
+	MessageKey      string         `json:"MessageKey"`
+	NumberOfArgs    int            `json:"NumberOfArgs"`
+	ParamTypes      []string       `json:"ParamTypes,omitempty"`
+	ClearingLogic   *ClearingLogic `json:"ClearingLogic,omitempty"`
+}
+
+// Resolved is a message resolved from a registry
+type Resolved struct {
+	Message    string
+	Severity   string
+	Resolution string
+	Metadata
+}
+
+// Summary describes a loaded registry
+type Summary struct {
+	RegistryPrefix  string `json:"RegistryPrefix"`
+	RegistryVersion string `json:"RegistryVersion"`
+	Language        string `json:"Language,omitempty"`
+	OwningEntity    string `json:"OwningEntity,omitempty"`
+	Messages        int    `json:"Messages"`
+}
+
+// Parse unmarshals a message registry
+func Parse(b []byte) (*Registry, error) {
+	r := &Registry{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(r.OdataType, registryOdataType) {
+		return nil, fmt.Errorf("@odata.type %q is not a message registry", r.OdataType)
+	}
+	if r.RegistryPrefix == "" || r.RegistryVersion == "" {
+		return nil, fmt.Errorf("registry %q has no RegistryPrefix or RegistryVersion", r.ID)
+	}
+	version, ok := parseVersion(strings.Split(r.RegistryVersion, "."))
+	if !ok {
+		return nil, fmt.Errorf("registry %q has an invalid RegistryVersion %q", r.ID, r.RegistryVersion)
+	}
+	r.version = version
+	return r, nil
+}
+
+// Resolver holds the loaded registries, newest version first for every prefix
+type Resolver struct {
+	sync.RWMutex
+	registries map[string][]*Registry
+}
+
+// NewResolver creates a resolver without registries
+func NewResolver() *Resolver {
+	return &Resolver{registries: map[string][]*Registry{}}
+}
+
+// Add adds a registry, replacing the registry with the same prefix and version.
+// An English registry is not replaced by a translation.
+func (rs *Resolver) Add(r *Registry) {
+	rs.Lock()
+	defer rs.Unlock()
+	versions := rs.registries[r.RegistryPrefix]
+	for i, existing := range versions {
+		if existing.RegistryVersion == r.RegistryVersion {
+			if isEnglish(existing.Language) && !isEnglish(r.Language) {
+				return
+			}
+			versions[i] = r
+			return
+		}
+	}
+	versions = append(versions, r)
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i].version, versions[j].version) > 0
+	})
+	rs.registries[r.RegistryPrefix] = versions
+}
+
+// List returns the loaded registries by prefix, newest version first
+func (rs *Resolver) List() []Summary {
+	rs.RLock()
+	defer rs.RUnlock()
+	prefixes := make([]string, 0, len(rs.registries))
+	for p := range rs.registries {
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+	summaries := []Summary{}
+	for _, p := range prefixes {
+		for _, r := range rs.registries[p] {
+			summaries = append(summaries, Summary{
+				RegistryPrefix:  r.RegistryPrefix,
+				RegistryVersion: r.RegistryVersion,
+				Language:        r.Language,
+				OwningEntity:    r.OwningEntity,
+				Messages:        len(r.Messages),
+			})
+		}
+	}
+	return summaries
+}
+
+// LoadFile adds the registry of a JSON file
+func (rs *Resolver) LoadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	r, err := Parse(b)
+	if err != nil {
+		return fmt.Errorf("failed to load registry %s: %v", path, err)
+	}
+	rs.Add(r)
+	return nil
+}
+
+// Resolve looks up a MessageId, e.g. Base.1.8.AccessDenied, and substitutes the message arguments.
+// When the registry version of the MessageId is not loaded, the newest version with the same major
+// version is used, then the newest version. MessageIds without a registry, e.g. TMP0100, are
+// looked up in all the registries.
+func (rs *Resolver) Resolve(messageID string, args []string) (Resolved, error) {
+	rs.RLock()
+	defer rs.RUnlock()
+	prefix, version, key := splitMessageID(messageID)
+	var candidates []*Registry
+	if prefix == "" {
+		prefixes := make([]string, 0, len(rs.registries))
+		for p := range rs.registries {
+			prefixes = append(prefixes, p)
+		}
+		sort.Strings(prefixes)
+		for _, p := range prefixes {
+			candidates = append(candidates, rs.registries[p]...)
+		}
+	} else {
+		candidates = rs.candidates(prefix, version)
+	}
+	for _, r := range candidates {
+		if m, ok := r.Messages[key]; ok {
+			return Resolved{
+				Message:    substitute(m.Message, args),
+				Severity:   m.severity(),
+				Resolution: m.Resolution,
+				Metadata: Metadata{
+					RegistryPrefix:  r.RegistryPrefix,
+					RegistryVersion: r.RegistryVersion,
+					MessageKey:      key,
+					NumberOfArgs:    m.NumberOfArgs,
+					ParamTypes:      m.ParamTypes,
+					ClearingLogic:   m.ClearingLogic,
+				},
+			}, nil
+		}
+	}
+	return Resolved{}, fmt.Errorf("%w: %s", ErrUnknownMessage, messageID)
+}
+
+// candidates returns the registries of a prefix in lookup order: the MessageId version,
+// the newer then older versions of the same major version, then the other major versions
+func (rs *Resolver) candidates(prefix string, version []int) []*Registry {
+	var exact, sameMajor, others []*Registry
+	for _, r := range rs.registries[prefix] {
+		switch {
+		case len(version) > 0 && hasVersion(r.version, version):
+			exact = append(exact, r)
+		case len(version) > 0 && r.version[0] == version[0]:
+			sameMajor = append(sameMajor, r)
+		default:
+			others = append(others, r)
+		}
+	}
+	return append(append(exact, sameMajor...), others...)
+}
+
+// severity returns MessageSeverity, or the deprecated Severity
+func (m Message) severity() string {
+	if m.MessageSeverity != "" {
+		return m.MessageSeverity
+	}
+	return m.Severity
+}
+
+// substitute replaces the %1..%n placeholders with the arguments,
+// placeholders without an argument are kept
+func substitute(message string, args []string) string {
+	return argRegexp.ReplaceAllStringFunc(message, func(placeholder string) string {
+		i, err := strconv.Atoi(placeholder[1:])
+		if err != nil || i < 1 || i > len(args) {
+			return placeholder
+		}
+		return args[i-1]
+	})
+}
+
+// splitMessageID splits a MessageId into the registry prefix, version and message key.
+// The version has the major and minor numbers, some services also send the errata number.
+func splitMessageID(messageID string) (prefix string, version []int, key string) {
+	parts := strings.Split(messageID, ".")
+	if len(parts) == 1 {
+		return "", nil, messageID
+	}
+	key = parts[len(parts)-1]
+	for i := 1; i < len(parts)-1; i++ {
+		if v, ok := parseVersion(parts[i : len(parts)-1]); ok {
+			return strings.Join(parts[:i], "."), v, key
+		}
+	}
+	return strings.Join(parts[:len(parts)-1], "."), nil, key
+}
+
+func parseVersion(parts []string) ([]int, bool) {
+	version := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		version = append(version, n)
+	}
+	return version, len(version) > 0
+}
+
+// hasVersion returns true if the registry version starts with the MessageId version
+func hasVersion(registryVersion, version []int) bool {
+	if len(registryVersion) < len(version) {
+		return false
+	}
+	return compareVersions(registryVersion[:len(version)], version) == 0
+}
+
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return len(a) - len(b)
+}
+
+func isEnglish(language string) bool {
+	language = strings.ToLower(language)
+	return language == "" || language == "en" || strings.HasPrefix(language, "en-")
+}
@@ -0,0 +1,93 @@
+//go:build unittests
+// +build unittests
+
+package registry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	rs := NewResolver()
+	count, err := rs.LoadDir("testdata")
+	assert.NoError(t, err)
+	// Base.json is a registry file, not a registry
+	assert.Equal(t, 3, count)
+
+	r, err := rs.Resolve("Base.1.8.PropertyValueNotInList", []string{"Foo", "Context"})
+	assert.NoError(t, err)
+	assert.Equal(t, "The value Foo for the property Context is not in the list of acceptable values.", r.Message)
+	assert.Equal(t, "Warning", r.Severity)
+	assert.Equal(t, "1.8.1", r.RegistryVersion)
+	assert.Equal(t, "PropertyValueNotInList", r.MessageKey)
+
+	// exact version
+	r, err = rs.Resolve("Base.1.4.AccessDenied", []string{"/redfish/v1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "While attempting to establish a connection to /redfish/v1, the service was denied access.", r.Message)
+	assert.Equal(t, "1.4.0", r.RegistryVersion)
+
+	// version fallback to the newest loaded version
+	r, err = rs.Resolve("Base.1.6.AccessDenied", []string{"/redfish/v1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1.8.1", r.RegistryVersion)
+
+	// errata version and OEM registry
+	r, err = rs.Resolve("IDRAC.2.8.0.TMP0100", []string{"Inlet"})
+	assert.NoError(t, err)
+	assert.Equal(t, "The system board Inlet temperature is less than the lower warning threshold.", r.Message)
+	assert.Equal(t, "Warning", r.Severity)
+
+	// registry metadata
+	r, err = rs.Resolve("IDRAC.2.8.TMP0120", []string{"Inlet"})
+	assert.NoError(t, err)
+	assert.Equal(t, Metadata{
+		RegistryPrefix:  "IDRAC",
+		RegistryVersion: "2.8.0",
+		MessageKey:      "TMP0120",
+		NumberOfArgs:    1,
+		ParamTypes:      []string{"string"},
+		ClearingLogic:   &ClearingLogic{ClearsIf: "SameOriginOfCondition", ClearsMessage: []string{"TMP0100"}},
+	}, r.Metadata)
+
+	// MessageId without registry, missing arguments are kept
+	r, err = rs.Resolve("TMP0100", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "The system board %1 temperature is less than the lower warning threshold.", r.Message)
+
+	_, err = rs.Resolve("Base.1.8.Unknown", nil)
+	assert.True(t, errors.Is(err, ErrUnknownMessage))
+	_, err = rs.Resolve("Other.1.0.AccessDenied", nil)
+	assert.True(t, errors.Is(err, ErrUnknownMessage))
+}
+
+func TestList(t *testing.T) {
+	rs := NewResolver()
+	_, err := rs.LoadDir("testdata")
+	assert.NoError(t, err)
+	assert.Equal(t, []Summary{
+		{RegistryPrefix: "Base", RegistryVersion: "1.8.1", Language: "en", OwningEntity: "DMTF", Messages: 2},
+		{RegistryPrefix: "Base", RegistryVersion: "1.4.0", Language: "en", OwningEntity: "DMTF", Messages: 1},
+		{RegistryPrefix: "IDRAC", RegistryVersion: "2.8.0", Language: "En", OwningEntity: "Dell", Messages: 2},
+	}, rs.List())
+}
+
+func TestSubstitute(t *testing.T) {
+	args := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	assert.Equal(t, "j a 100%", substitute("%10 %1 100%", args))
+}
+
+func TestSplitMessageID(t *testing.T) {
+	prefix, version, key := splitMessageID("Base.1.8.AccessDenied")
+	assert.Equal(t, "Base", prefix)
+	assert.Equal(t, []int{1, 8}, version)
+	assert.Equal(t, "AccessDenied", key)
+
+	prefix, version, key = splitMessageID("Contoso.Fan.1.0.Failed")
+	assert.Equal(t, "Contoso.Fan", prefix)
+	assert.Equal(t, []int{1, 0}, version)
+	assert.Equal(t, "Failed", key)
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// UnresolvedID is a MessageId that could not be resolved
+type UnresolvedID struct {
+	MessageID string    `json:"MessageId"`
+	Count     uint64    `json:"Count"`
+	LastSeen  time.Time `json:"LastSeen"`
+	// Error is the last error returned for the MessageId
+	Error string `json:"Error,omitempty"`
+}
+
+// Unresolved counts the MessageIds that could not be resolved,
+// the least recently seen MessageId is dropped when capacity is reached
+type Unresolved struct {
+	mu       sync.Mutex
+	capacity int
+	ids      map[string]*UnresolvedID
+	now      func() time.Time
+}
+
+// NewUnresolved creates a counter of up to capacity MessageIds
+func NewUnresolved(capacity int) *Unresolved {
+	return &Unresolved{
+		capacity: capacity,
+		ids:      map[string]*UnresolvedID{},
+		now:      time.Now,
+	}
+}
+
+// Add counts a MessageId, a nil err keeps the last error
+func (u *Unresolved) Add(messageID string, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	id, ok := u.ids[messageID]
+	if !ok {
+		if len(u.ids) >= u.capacity {
+			u.dropOldest()
+		}
+		id = &UnresolvedID{MessageID: messageID}
+		u.ids[messageID] = id
+	}
+	id.Count++
+	id.LastSeen = u.now()
+	if err != nil {
+		id.Error = err.Error()
+	}
+}
+
+// List returns the MessageIds, most recently seen first
+func (u *Unresolved) List() []UnresolvedID {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	ids := make([]UnresolvedID, 0, len(u.ids))
+	for _, id := range u.ids {
+		ids = append(ids, *id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if !ids[i].LastSeen.Equal(ids[j].LastSeen) {
+			return ids[i].LastSeen.After(ids[j].LastSeen)
+		}
+		return ids[i].MessageID < ids[j].MessageID
+	})
+	return ids
+}
+
+func (u *Unresolved) dropOldest() {
+	var oldest *UnresolvedID
+	for _, id := range u.ids {
+		if oldest == nil || id.LastSeen.Before(oldest.LastSeen) {
+			oldest = id
+		}
+	}
+	if oldest != nil {
+		delete(u.ids, oldest.MessageID)
+	}
+}
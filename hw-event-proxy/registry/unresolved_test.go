@@ -0,0 +1,40 @@
+//go:build unittests
+// +build unittests
+
+package registry
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnresolved(t *testing.T) {
+	now := time.Now()
+	u := NewUnresolved(2)
+	u.now = func() time.Time { return now }
+
+	u.Add("TMP0000", fmt.Errorf("%w: TMP0000", ErrUnknownMessage))
+	now = now.Add(time.Second)
+	u.Add("FAN0000", nil)
+	now = now.Add(time.Second)
+	u.Add("TMP0000", nil)
+
+	ids := u.List()
+	assert.Len(t, ids, 2)
+	assert.Equal(t, "TMP0000", ids[0].MessageID)
+	assert.Equal(t, uint64(2), ids[0].Count)
+	// the last error is kept
+	assert.Equal(t, "unable to find message in Redfish Registries: TMP0000", ids[0].Error)
+	assert.Equal(t, "FAN0000", ids[1].MessageID)
+
+	// the least recently seen MessageId is dropped
+	now = now.Add(time.Second)
+	u.Add("PSU0000", nil)
+	ids = u.List()
+	assert.Len(t, ids, 2)
+	assert.Equal(t, "PSU0000", ids[0].MessageID)
+	assert.Equal(t, "TMP0000", ids[1].MessageID)
+}
@@ -16,87 +16,166 @@ package restclient
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"time"
 
 	"github.com/redhat-cne/sdk-go/pkg/types"
 	log "github.com/sirupsen/logrus"
-
-	"golang.org/x/net/context"
 )
 
-var (
-	httpTimeout = 2 * time.Second
+const (
+	// DefaultTimeout is the timeout of a request attempt when none is given
+	DefaultTimeout = 2 * time.Second
+	// DefaultRetries is the number of retries of idempotent requests
+	DefaultRetries = 3
+	// backoff between the retries, a random duration up to the doubled backoff is waited
+	minBackoff = 100 * time.Millisecond
+	maxBackoff = 2 * time.Second
 )
 
-// Rest client to make http request
+// StatusError is returned for responses with a status other than 2xx
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s returned status %d", e.Method, e.URL, e.StatusCode)
+}
+
+// StatusCode returns the status of a StatusError, or 0 for the other errors
+func StatusCode(err error) int {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+	return 0
+}
+
+// Rest client to make http request. Idempotent requests are retried
+// with a jittered exponential backoff on connection errors and 5xx statuses.
 type Rest struct {
 	client http.Client
+	// Retries is the number of retries of idempotent requests
+	Retries int
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
 }
 
-// New get new rest client
-func New() *Rest {
+// New get new rest client, timeout applies to every attempt of a request
+func New(timeout time.Duration) *Rest {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
 	return &Rest{
 		client: http.Client{
-			Timeout: httpTimeout,
+			Timeout: timeout,
 		},
+		Retries:    DefaultRetries,
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
 	}
 }
 
 // Post post with data
-func (r *Rest) Post(url *types.URI, data []byte) int {
-	ctx := context.Background()
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-	request, err := http.NewRequestWithContext(ctx, "POST", url.String(), bytes.NewBuffer(data))
-	if err != nil {
-		log.Errorf("error creating post request %v", err)
-		return http.StatusBadRequest
-	}
-	request.Header.Set("content-type", "application/json")
-	response, err := r.client.Do(request)
-	if err != nil {
-		log.Errorf("error in post response %v", err)
-		return http.StatusBadRequest
+func (r *Rest) Post(ctx context.Context, url *types.URI, data []byte) error {
+	_, _, err := r.Do(ctx, http.MethodPost, url, data)
+	return err
+}
+
+// PostWithReturn post with data and return the status and data
+func (r *Rest) PostWithReturn(ctx context.Context, url *types.URI, data []byte) (int, []byte, error) {
+	return r.Do(ctx, http.MethodPost, url, data)
+}
+
+// PostWithRetry post with data and return the status and data, the request is retried like the
+// idempotent requests. It is meant for the requests the server handles idempotently, such as the
+// creation of a publisher, which returns the existing publisher of the resource.
+func (r *Rest) PostWithRetry(ctx context.Context, url *types.URI, data []byte) (int, []byte, error) {
+	return r.send(ctx, http.MethodPost, url, data, r.Retries)
+}
+
+// Do sends a request and returns the status and body of the response. A *StatusError is returned
+// for statuses other than 2xx. GET, HEAD, PUT and DELETE requests are retried.
+func (r *Rest) Do(ctx context.Context, method string, url *types.URI, data []byte) (int, []byte, error) {
+	retries := 0
+	if idempotent(method) {
+		retries = r.Retries
 	}
-	if response.Body != nil {
-		defer response.Body.Close()
-		// read any content and print
-		body, readErr := io.ReadAll(response.Body)
-		if readErr == nil && len(body) > 0 {
-			log.Debugf("%s return response %s\n", url.String(), string(body))
+	return r.send(ctx, method, url, data, retries)
+}
+
+// send sends a request, retrying it with a jittered exponential backoff on connection errors and 5xx statuses
+func (r *Rest) send(ctx context.Context, method string, url *types.URI, data []byte, retries int) (int, []byte, error) {
+	backoff := r.minBackoff
+	for attempt := 0; ; attempt++ {
+		status, body, err := r.do(ctx, method, url, data)
+		if err == nil || attempt >= retries || ctx.Err() != nil || !retryable(err) {
+			return status, body, err
+		}
+		wait := time.Duration(rand.Int63n(int64(backoff)) + 1) //nolint:gosec
+		log.Debugf("%v, retrying in %s", err, wait)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return status, body, err
 		}
-		if response.StatusCode == http.StatusBadRequest {
-			log.Errorf("%v\n", string(body))
+		if backoff *= 2; backoff > r.maxBackoff {
+			backoff = r.maxBackoff
 		}
 	}
-	return response.StatusCode
 }
 
-// PostWithReturn post with data and return data
-func (r *Rest) PostWithReturn(url *types.URI, data []byte) (int, []byte) {
-	ctx := context.Background()
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-	request, err := http.NewRequestWithContext(ctx, "POST", url.String(), bytes.NewBuffer(data))
+func (r *Rest) do(ctx context.Context, method string, url *types.URI, data []byte) (int, []byte, error) {
+	var reqBody io.Reader
+	if data != nil {
+		reqBody = bytes.NewBuffer(data)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, url.String(), reqBody)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error creating %s request: %w", method, err)
+	}
+	if data != nil {
+		request.Header.Set("content-type", "application/json")
+	}
+	response, err := r.client.Do(request)
 	if err != nil {
-		log.Errorf("error creating post request %v", err)
-		return http.StatusBadRequest, nil
+		return 0, nil, err
 	}
-	request.Header.Set("content-type", "application/json")
-	res, err := r.client.Do(request)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		log.Errorf("error in post response %v to %s ", err, url)
-		return http.StatusBadRequest, nil
+		return response.StatusCode, nil, fmt.Errorf("error reading %s %s response: %w", method, url, err)
+	}
+	if len(body) > 0 {
+		log.Debugf("%s return response %s", url.String(), string(body))
 	}
-	if res.Body != nil {
-		defer res.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, body, &StatusError{Method: method, URL: url.String(), StatusCode: response.StatusCode, Body: body}
 	}
+	return response.StatusCode, body, nil
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
 
-	body, readErr := io.ReadAll(res.Body)
-	if readErr != nil {
-		return http.StatusBadRequest, nil
+// retryable returns true for connection errors, including the timeout of an attempt, and 5xx statuses
+func retryable(err error) bool {
+	if status := StatusCode(err); status != 0 {
+		return status >= 500
 	}
-	return res.StatusCode, body
+	return true
 }
@@ -0,0 +1,83 @@
+//go:build unittests
+// +build unittests
+
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redhat-cne/sdk-go/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRest() *Rest {
+	r := New(time.Second)
+	r.minBackoff, r.maxBackoff = time.Millisecond, time.Millisecond
+	return r
+}
+
+func TestPostWithRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"id":"1"}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	status, body, err := newRest().PostWithRetry(context.Background(), types.ParseURI(server.URL), []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"id":"1"}`, string(body))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestPostStatusError(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := newRest().Post(context.Background(), types.ParseURI(server.URL), []byte(`{}`))
+	assert.Equal(t, http.StatusInternalServerError, StatusCode(err))
+	// POST is not idempotent
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestPostWithRetryNotRetried(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, _, err := newRest().PostWithRetry(context.Background(), types.ParseURI(server.URL), []byte(`{}`))
+	assert.Equal(t, http.StatusNotFound, StatusCode(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	r := newRest()
+	_, _, err := r.PostWithRetry(context.Background(), types.ParseURI(url), []byte(`{}`))
+	assert.Error(t, err)
+	assert.Equal(t, 0, StatusCode(err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = r.PostWithRetry(ctx, types.ParseURI(url), []byte(`{}`))
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,216 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/bmc"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/eventrecord"
+)
+
+const (
+	eventServicePath = "/redfish/v1/EventService"
+	// default reconnection backoff
+	minBackoff = 1 * time.Second
+	maxBackoff = 60 * time.Second
+	// maximum size of an SSE line
+	maxLineSize = 1048576
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// Handler is called for every Redfish event read from the stream, ctx is canceled when the client is stopped
+type Handler func(ctx context.Context, e eventrecord.Event)
+
+// Client reads Redfish events from the Server-Sent Events stream of a BMC
+type Client struct {
+	bmcID      string
+	conn       *bmc.Connection
+	httpClient *http.Client
+	handler    Handler
+	// uri is the ServerSentEventUri of the EventService, discovered when empty
+	uri         string
+	lastEventID string
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+}
+
+// eventService is the part of the Redfish EventService used by the client
+type eventService struct {
+	ServerSentEventURI string `json:"ServerSentEventUri"`
+}
+
+// NewClient creates a SSE client for the BMC
+func NewClient(bmcID string, conn *bmc.Connection, handler Handler) *Client {
+	return &Client{
+		bmcID:      bmcID,
+		conn:       conn,
+		httpClient: conn.HTTPClient(0),
+		handler:    handler,
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
+	}
+}
+
+// Run reads the stream until stopCh is closed, reconnecting with an
+// exponential backoff and resuming from the last received event ID
+func (c *Client) Run(stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	backoff := c.minBackoff
+	for {
+		connected, err := c.stream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = c.minBackoff
+		}
+		if err != nil {
+			log.Errorf("SSE stream of BMC %s: %v, reconnecting in %s", c.bmcID, err, backoff)
+		} else {
+			log.Infof("SSE stream of BMC %s closed, reconnecting in %s", c.bmcID, backoff)
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		if backoff *= 2; backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+// discover reads the ServerSentEventUri of the EventService
+func (c *Client) discover(ctx context.Context) error {
+	service := eventService{}
+	if err := c.conn.Get(ctx, c.conn.HTTPClient(30*time.Second), eventServicePath, &service); err != nil {
+		return err
+	}
+	if service.ServerSentEventURI == "" {
+		return fmt.Errorf("the EventService of the BMC has no ServerSentEventUri")
+	}
+	c.uri = service.ServerSentEventURI
+	log.Infof("SSE stream of BMC %s is %s", c.bmcID, c.uri)
+	return nil
+}
+
+// stream connects to the stream and reads it until it is closed,
+// connected is true if the connection was established
+func (c *Client) stream(ctx context.Context) (connected bool, err error) {
+	if c.uri == "" {
+		if err = c.discover(ctx); err != nil {
+			return false, err
+		}
+	}
+	req, err := c.conn.NewRequest(ctx, http.MethodGet, c.uri)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if c.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", c.lastEventID)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusNotFound {
+			// the URI may have changed after a firmware update
+			c.uri = ""
+		}
+		return false, fmt.Errorf("get %s returned status %d", req.URL, resp.StatusCode)
+	}
+	log.Infof("connected to SSE stream of BMC %s", c.bmcID)
+	return true, c.read(ctx, resp.Body)
+}
+
+// read parses the text/event-stream format and dispatches the data of every message
+func (c *Client) read(ctx context.Context, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	var data []string
+	id := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				c.dispatch(ctx, strings.Join(data, "\n"))
+			}
+			if id != "" {
+				c.lastEventID = id
+			}
+			data, id = nil, ""
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// comment, used as keep-alive
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			data = append(data, value)
+		case "id":
+			id = value
+		case "retry":
+			if ms, err := time.ParseDuration(value + "ms"); err == nil && ms > 0 {
+				c.minBackoff = ms
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// dispatch decodes the data of a message and passes Redfish events to the handler,
+// other payloads such as metric reports are ignored
+func (c *Client) dispatch(ctx context.Context, data string) {
+	var payload struct {
+		OdataType string `json:"@odata.type"`
+	}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		log.Errorf("failed to unmarshal SSE message from BMC %s: %v", c.bmcID, err)
+		return
+	}
+	if !strings.HasPrefix(payload.OdataType, "#Event.") {
+		log.Debugf("ignored SSE message of type %s from BMC %s", payload.OdataType, c.bmcID)
+		return
+	}
+	e, err := eventrecord.Decode([]byte(data))
+	if err != nil {
+		log.Errorf("failed to unmarshal SSE event from BMC %s: %v", c.bmcID, err)
+		return
+	}
+	c.handler(ctx, e)
+}
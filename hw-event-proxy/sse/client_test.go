@@ -0,0 +1,60 @@
+//go:build unittests
+// +build unittests
+
+package sse
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/bmc"
+	"github.com/redhat-cne/hw-event-proxy/hw-event-proxy/eventrecord"
+)
+
+func TestClient(t *testing.T) {
+	lastEventIDs := make(chan string, 2)
+	mux := http.NewServeMux()
+	mux.HandleFunc(eventServicePath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ServerSentEventUri": "/redfish/v1/EventService/SSE"}`)
+	})
+	mux.HandleFunc("/redfish/v1/EventService/SSE", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "root", user)
+		assert.Equal(t, "calvin", pass)
+		select {
+		case lastEventIDs <- r.Header.Get("Last-Event-ID"):
+		default:
+		}
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "id: 1\ndata: {\"@odata.type\": \"#MetricReport.v1_0_0.MetricReport\"}\n\n")
+		fmt.Fprint(w, "id: 2\ndata: {\"@odata.type\": \"#Event.v1_4_0.Event\", \"Id\": \"2\",\n")
+		fmt.Fprint(w, "data: \"Events\": [{\"MessageId\": \"TMP0100\"}]}\n\n")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	events := make(chan eventrecord.Event, 2)
+	c := NewClient("test", &bmc.Connection{Address: server.URL, Username: "root", Password: "calvin"}, func(_ context.Context, e eventrecord.Event) {
+		select {
+		case events <- e:
+		default:
+		}
+	})
+	c.minBackoff = 10 * time.Millisecond
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go c.Run(stopCh)
+
+	e := <-events
+	assert.Equal(t, "2", e.Redfish.ID)
+	assert.Equal(t, "TMP0100", e.Redfish.Events[0].MessageID)
+	assert.Equal(t, "", <-lastEventIDs)
+	// the stream is closed by the server, the client resumes from the last event
+	assert.Equal(t, "2", <-lastEventIDs)
+}
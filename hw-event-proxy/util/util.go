@@ -35,6 +35,14 @@ func GetIntEnv(key string, fallback int) int {
 	return fallback
 }
 
+// GetStringEnv get string value from env
+func GetStringEnv(key, fallback string) string {
+	if val, ok := os.LookupEnv(key); ok && val != "" {
+		return val
+	}
+	return fallback
+}
+
 // InitLogger initilaize logger
 func InitLogger() {
 	lvl, ok := os.LookupEnv("LOG_LEVEL")
@@ -0,0 +1,188 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/redhat-cne/sdk-go/pkg/event/redfish"
+)
+
+// Mode defines how events violating the Redfish Event schema are handled
+type Mode string
+
+const (
+	// Strict rejects events with violations
+	Strict Mode = "strict"
+	// Warn logs the violations and forwards the event as is,
+	// except for the properties required to publish it
+	Warn Mode = "warn"
+	// Repair fills the missing properties that have a sensible default
+	// and forwards the event
+	Repair Mode = "repair"
+)
+
+const (
+	// defaultOdataType is used to repair events without @odata.type
+	defaultOdataType = "#Event.v1_3_0.Event"
+	// defaultEventType is used to repair records without EventType
+	defaultEventType = "Alert"
+	// defaultName is used to repair events without Name
+	defaultName = "Event Array"
+)
+
+// ViolationType is the kind of schema violation
+type ViolationType string
+
+const (
+	// Missing is a required property that is absent or empty
+	Missing ViolationType = "missing"
+	// Invalid is a property with a value the schema does not allow
+	Invalid ViolationType = "invalid"
+)
+
+// odataTypeRegexp matches the @odata.type of Event v1_0_0 and later
+var odataTypeRegexp = regexp.MustCompile(`^#Event\.v(\d+)_(\d+)_(\d+)\.Event$`)
+
+// Violation is a property of the event that does not follow the schema
+type Violation struct {
+	// Field is the path of the property, e.g. Events[0].MemberId
+	Field string
+	Type  ViolationType
+	// Value is the value of invalid properties
+	Value string
+	// Repaired is true if the property was set to a default value
+	Repaired bool
+}
+
+func (v Violation) String() string {
+	s := fmt.Sprintf("%s is %s", v.Field, v.Type)
+	if v.Type == Invalid {
+		s = fmt.Sprintf("%s %q is %s", v.Field, v.Value, v.Type)
+	}
+	if v.Repaired {
+		s += ", repaired"
+	}
+	return s
+}
+
+// Error is returned in Strict mode for events with violations
+type Error struct {
+	Violations []Violation
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("event has %d schema violations, first: %s", len(e.Violations), e.Violations[0])
+}
+
+// ParseMode converts a string to a Mode
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case Strict, Warn, Repair:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown validation mode %q, must be one of %s, %s or %s", s, Strict, Warn, Repair)
+}
+
+// schemaVersion returns the minor version of the Event schema, e.g. 3 for #Event.v1_3_0.Event
+func schemaVersion(odataType string) (minor int, ok bool) {
+	m := odataTypeRegexp.FindStringSubmatch(odataType)
+	if m == nil || m[1] != "1" {
+		return 0, false
+	}
+	minor, _ = strconv.Atoi(m[2])
+	return minor, true
+}
+
+// Validate checks the event against the Redfish Event schema, from #Event.v1_0_0
+// to v1_7 and later. In Repair mode the event is modified in place.
+// An error is returned only in Strict mode, when there are violations.
+// In every mode the forwarded events get the properties required to publish them.
+func Validate(e *redfish.Event, mode Mode) ([]Violation, error) {
+	var violations []Violation
+	add := func(field string, t ViolationType, value string, repair func()) {
+		v := Violation{Field: field, Type: t, Value: value}
+		if mode == Repair && repair != nil {
+			repair()
+			v.Repaired = true
+		}
+		violations = append(violations, v)
+	}
+
+	// EventType is deprecated starting with Event v1_3 but required before
+	eventTypeRequired := false
+	switch minor, ok := schemaVersion(e.OdataType); {
+	case e.OdataType == "":
+		add("@odata.type", Missing, "", func() { e.OdataType = defaultOdataType })
+	case !ok:
+		add("@odata.type", Invalid, e.OdataType, nil)
+	default:
+		eventTypeRequired = minor < 3
+	}
+	if e.ID == "" {
+		add("Id", Missing, "", nil)
+	}
+	if e.Name == "" {
+		add("Name", Missing, "", func() { e.Name = defaultName })
+	}
+	if len(e.Events) == 0 {
+		add("Events", Missing, "", nil)
+	}
+
+	for i := range e.Events {
+		r := &e.Events[i]
+		path := fmt.Sprintf("Events[%d]", i)
+		if r.MemberID == "" {
+			index := strconv.Itoa(i)
+			add(path+".MemberId", Missing, "", func() { r.MemberID = index })
+		}
+		if r.MessageID == "" {
+			add(path+".MessageId", Missing, "", nil)
+		}
+		if r.EventType == "" && eventTypeRequired {
+			add(path+".EventType", Missing, "", func() { r.EventType = defaultEventType })
+		}
+	}
+
+	if mode == Strict && len(violations) > 0 {
+		return violations, &Error{Violations: violations}
+	}
+	fillPublishRequired(e, violations)
+	return violations, nil
+}
+
+// fillPublishRequired sets the properties without which sdk-go fails to marshal the event, Name and
+// the EventType of every record, even though EventType is deprecated starting with Event v1_3
+func fillPublishRequired(e *redfish.Event, violations []Violation) {
+	repaired := func(field string) {
+		for i := range violations {
+			if violations[i].Field == field {
+				violations[i].Repaired = true
+			}
+		}
+	}
+	if e.Name == "" {
+		e.Name = defaultName
+		repaired("Name")
+	}
+	for i := range e.Events {
+		if r := &e.Events[i]; r.EventType == "" {
+			r.EventType = defaultEventType
+			repaired(fmt.Sprintf("Events[%d].EventType", i))
+		}
+	}
+}
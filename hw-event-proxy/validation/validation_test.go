@@ -0,0 +1,81 @@
+//go:build unittests
+// +build unittests
+
+package validation
+
+import (
+	"bytes"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/redhat-cne/sdk-go/pkg/event/redfish"
+	"github.com/stretchr/testify/assert"
+)
+
+func invalidEvent() redfish.Event {
+	return redfish.Event{
+		OdataType: "#Event.v1_0_0.Event",
+		ID:        "1",
+		Events: []redfish.EventRecord{
+			{MemberID: "0", MessageID: "TMP0100", EventType: "Alert"},
+			{},
+		},
+	}
+}
+
+func TestValidateValidEvent(t *testing.T) {
+	e := redfish.Event{
+		OdataType: "#Event.v1_7_0.Event",
+		ID:        "1",
+		Name:      "Event Array",
+		Events:    []redfish.EventRecord{{MemberID: "0", MessageID: "TMP0100"}},
+	}
+	violations, err := Validate(&e, Strict)
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+	// EventType is deprecated but required by sdk-go
+	assert.Equal(t, "Alert", e.Events[0].EventType)
+}
+
+func TestValidateStrict(t *testing.T) {
+	e := invalidEvent()
+	violations, err := Validate(&e, Strict)
+	assert.Error(t, err)
+	var fields []string
+	for _, v := range violations {
+		fields = append(fields, v.Field)
+	}
+	assert.Equal(t, []string{"Name", "Events[1].MemberId", "Events[1].MessageId", "Events[1].EventType"}, fields)
+}
+
+func TestValidateWarn(t *testing.T) {
+	e := invalidEvent()
+	violations, err := Validate(&e, Warn)
+	assert.NoError(t, err)
+	assert.Len(t, violations, 4)
+	assert.Equal(t, "", e.Events[1].MemberID)
+	// sdk-go can't marshal the event without Name and EventType
+	assert.Equal(t, "Event Array", e.Name)
+	assert.True(t, violations[0].Repaired)
+	assert.Equal(t, "Alert", e.Events[1].EventType)
+	var b bytes.Buffer
+	assert.NoError(t, redfish.WriteJSONEvent(&e, &b, jsoniter.NewStream(jsoniter.ConfigCompatibleWithStandardLibrary, &b, 0)))
+}
+
+func TestValidateRepair(t *testing.T) {
+	e := invalidEvent()
+	violations, err := Validate(&e, Repair)
+	assert.NoError(t, err)
+	assert.Len(t, violations, 4)
+	assert.Equal(t, "Event Array", e.Name)
+	assert.Equal(t, "1", e.Events[1].MemberID)
+	assert.Equal(t, "Alert", e.Events[1].EventType)
+	// MessageId has no default
+	assert.False(t, violations[2].Repaired)
+}
+
+func TestValidateOdataType(t *testing.T) {
+	e := redfish.Event{OdataType: "#Event.Event", ID: "1", Name: "n", Events: []redfish.EventRecord{{MemberID: "0", MessageID: "m"}}}
+	violations, _ := Validate(&e, Warn)
+	assert.Equal(t, []Violation{{Field: "@odata.type", Type: Invalid, Value: "#Event.Event"}}, violations)
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// NoValidSession is the Base registry message returned for unauthenticated requests
+const NoValidSession = "Base.1.8.NoValidSession"
+
+// secretFile is a file holding one credential per line, reloaded when it changes
+type secretFile struct {
+	path    string
+	modTime time.Time
+	lines   []string
+}
+
+// load reads the file if it was modified since the last read
+func (f *secretFile) load() error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(f.modTime) && f.lines != nil {
+		return nil
+	}
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	lines := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	f.lines = lines
+	f.modTime = info.ModTime()
+	log.Infof("loaded %d credentials from %s", len(lines), f.path)
+	return nil
+}
+
+// contains compares s against every line in constant time
+func (f *secretFile) contains(s string) bool {
+	found := 0
+	for _, line := range f.lines {
+		found |= subtle.ConstantTimeCompare([]byte(line), []byte(s))
+	}
+	return found == 1
+}
+
+// Authenticator checks the credentials of webhook requests.
+// Basic credentials are read from a file of `username:password` lines and
+// bearer tokens from a file of one token per line. Both files are typically
+// mounted from a secret and are reloaded when they change.
+type Authenticator struct {
+	sync.Mutex
+	basic  *secretFile
+	bearer *secretFile
+}
+
+// NewAuthenticator creates an authenticator, an empty path disables the matching scheme
+func NewAuthenticator(basicAuthFile, bearerTokenFile string) *Authenticator {
+	a := &Authenticator{}
+	if basicAuthFile != "" {
+		a.basic = &secretFile{path: basicAuthFile}
+	}
+	if bearerTokenFile != "" {
+		a.bearer = &secretFile{path: bearerTokenFile}
+	}
+	return a
+}
+
+// Enabled returns true if at least one authentication scheme is configured
+func (a *Authenticator) Enabled() bool {
+	return a != nil && (a.basic != nil || a.bearer != nil)
+}
+
+// Authenticate returns nil if the request carries valid credentials
+func (a *Authenticator) Authenticate(r *http.Request) error {
+	if !a.Enabled() {
+		return nil
+	}
+	a.Lock()
+	defer a.Unlock()
+	if token, ok := bearerToken(r); ok && a.bearer != nil {
+		if err := a.bearer.load(); err != nil {
+			return fmt.Errorf("failed to load bearer tokens: %v", err)
+		}
+		if a.bearer.contains(token) {
+			return nil
+		}
+		return fmt.Errorf("invalid bearer token")
+	}
+	if username, password, ok := r.BasicAuth(); ok && a.basic != nil {
+		if err := a.basic.load(); err != nil {
+			return fmt.Errorf("failed to load basic auth credentials: %v", err)
+		}
+		if a.basic.contains(username + ":" + password) {
+			return nil
+		}
+		return fmt.Errorf("invalid credentials for user %q", username)
+	}
+	return fmt.Errorf("no credentials provided")
+}
+
+// Wrap returns a handler that rejects unauthenticated requests with 401
+// before calling next
+func (a *Authenticator) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	if !a.Enabled() {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := a.Authenticate(r); err != nil {
+			log.Warnf("rejected unauthenticated request from %s: %v", r.RemoteAddr, err)
+			if a.basic != nil {
+				w.Header().Add("WWW-Authenticate", `Basic realm="hw-event-proxy"`)
+			}
+			if a.bearer != nil {
+				w.Header().Add("WWW-Authenticate", `Bearer realm="hw-event-proxy"`)
+			}
+			WriteError(w, http.StatusUnauthorized, NoValidSession,
+				"There is no valid session established with the implementation.",
+				"Establish a session before attempting any operations.")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(auth[len(prefix):]), true
+}
@@ -0,0 +1,56 @@
+//go:build unittests
+// +build unittests
+
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticator(t *testing.T) {
+	dir := t.TempDir()
+	basicFile := filepath.Join(dir, "basic")
+	tokenFile := filepath.Join(dir, "tokens")
+	assert.NoError(t, os.WriteFile(basicFile, []byte("# comment\nbmc:secret\n"), 0600))
+	assert.NoError(t, os.WriteFile(tokenFile, []byte("token-1\ntoken-2\n"), 0600))
+
+	a := NewAuthenticator(basicFile, tokenFile)
+	h := a.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name   string
+		setup  func(r *http.Request)
+		status int
+	}{
+		{"no credentials", func(r *http.Request) {}, http.StatusUnauthorized},
+		{"valid basic", func(r *http.Request) { r.SetBasicAuth("bmc", "secret") }, http.StatusNoContent},
+		{"invalid basic", func(r *http.Request) { r.SetBasicAuth("bmc", "wrong") }, http.StatusUnauthorized},
+		{"valid token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer token-2") }, http.StatusNoContent},
+		{"invalid token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer token-3") }, http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+		tc.setup(req)
+		w := httptest.NewRecorder()
+		h(w, req)
+		assert.Equal(t, tc.status, w.Code, tc.name)
+		if tc.status == http.StatusUnauthorized {
+			assert.NotEmpty(t, w.Header().Values("WWW-Authenticate"), tc.name)
+			assert.Contains(t, w.Body.String(), NoValidSession, tc.name)
+		}
+	}
+}
+
+func TestAuthenticatorDisabled(t *testing.T) {
+	a := NewAuthenticator("", "")
+	assert.False(t, a.Enabled())
+	assert.NoError(t, a.Authenticate(httptest.NewRequest(http.MethodPost, "/webhook", nil)))
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"net/http"
+
+	jsoniter "github.com/json-iterator/go"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// messageType is the @odata.type of the messages in @Message.ExtendedInfo
+	messageType = "#Message.v1_1_1.Message"
+	// generalErrorMessage is the message of GeneralError in the Base registry
+	generalErrorMessage = "A general error has occurred. See ExtendedInfo for more information."
+)
+
+// Message IDs from the DMTF Base message registry used in error responses
+const (
+	MalformedJSON                 = "Base.1.8.MalformedJSON"
+	GeneralError                  = "Base.1.8.GeneralError"
+	InternalError                 = "Base.1.8.InternalError"
+	ResourceNotFound              = "Base.1.8.ResourceNotFound"
+	PropertyMissing               = "Base.1.8.PropertyMissing"
+	PropertyValueFormatError      = "Base.1.8.PropertyValueFormatError"
+	PropertyValueNotInList        = "Base.1.8.PropertyValueNotInList"
+	ServiceTemporarilyUnavailable = "Base.1.8.ServiceTemporarilyUnavailable"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// Message is an entry of @Message.ExtendedInfo as defined in Message.v1_1_1
+type Message struct {
+	OdataType       string   `json:"@odata.type"`
+	MessageID       string   `json:"MessageId"`
+	Message         string   `json:"Message"`
+	MessageArgs     []string `json:"MessageArgs,omitempty"`
+	Severity        string   `json:"Severity"`
+	MessageSeverity string   `json:"MessageSeverity"`
+	Resolution      string   `json:"Resolution,omitempty"`
+}
+
+// Error is the body of a Redfish error response
+type Error struct {
+	Code         string    `json:"code"`
+	Message      string    `json:"message"`
+	ExtendedInfo []Message `json:"@Message.ExtendedInfo"`
+}
+
+// ErrorResponse is the Redfish error response envelope
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+// NewMessage creates a message for @Message.ExtendedInfo
+func NewMessage(messageID, message, resolution string) Message {
+	return Message{
+		OdataType:       messageType,
+		MessageID:       messageID,
+		Message:         message,
+		Severity:        "Critical",
+		MessageSeverity: "Critical",
+		Resolution:      resolution,
+	}
+}
+
+// NewErrorResponse creates a Redfish error response with the given extended messages
+func NewErrorResponse(messages ...Message) ErrorResponse {
+	return ErrorResponse{
+		Error: Error{
+			Code:         GeneralError,
+			Message:      generalErrorMessage,
+			ExtendedInfo: messages,
+		},
+	}
+}
+
+// WriteError writes a Redfish error response with the given http status code
+func WriteError(w http.ResponseWriter, status int, messageID, message, resolution string) {
+	WriteErrorMessages(w, status, NewMessage(messageID, message, resolution))
+}
+
+// WriteErrorMessages writes a Redfish error response with several extended messages
+func WriteErrorMessages(w http.ResponseWriter, status int, messages ...Message) {
+	b, err := json.Marshal(NewErrorResponse(messages...))
+	if err != nil {
+		log.Errorf("failed to marshal error response: %v", err)
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err = w.Write(b); err != nil {
+		log.Errorf("failed to write error response: %v", err)
+	}
+}
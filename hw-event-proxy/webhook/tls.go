@@ -0,0 +1,154 @@
+// Copyright 2021 The Cloud Native Events Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/redhat-cne/sdk-go/pkg/util/wait"
+	log "github.com/sirupsen/logrus"
+)
+
+// CertReloader serves the webhook certificate and the CA bundle used to verify
+// BMC client certificates, and reloads them when the files change on disk
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+// NewCertReloader loads the certificate and key, and the client CA bundle if caFile is not empty
+func NewCertReloader(certFile, keyFile, caFile string) (*CertReloader, error) {
+	c := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		modTimes: map[string]time.Time{},
+	}
+	if _, err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Watch checks the files every interval and reloads them when they change
+func (c *CertReloader) Watch(interval time.Duration, stopCh <-chan struct{}) {
+	go wait.Until(func() {
+		reloaded, err := c.reload()
+		if err != nil {
+			log.Errorf("failed to reload webhook certificates, keeping the current ones: %v", err)
+		} else if reloaded {
+			log.Info("reloaded webhook certificates")
+		}
+	}, interval, stopCh)
+}
+
+// TLSConfig returns a TLS config that always serves the latest certificates.
+// Client certificates are required when a client CA bundle is configured.
+func (c *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// http.Server.ServeTLS loads the files given to it unless GetCertificate is set
+		GetCertificate: c.getCertificate,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+			cfg := &tls.Config{
+				MinVersion:     tls.VersionTLS12,
+				GetCertificate: c.getCertificate,
+			}
+			if c.clientCAs != nil {
+				cfg.ClientCAs = c.clientCAs
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// getCertificate returns the latest certificate
+func (c *CertReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
+
+// changed returns true if any of the files was modified since the last load
+func (c *CertReloader) changed() (bool, error) {
+	changed := false
+	for _, f := range []string{c.certFile, c.keyFile, c.caFile} {
+		if f == "" {
+			continue
+		}
+		info, err := os.Stat(f)
+		if err != nil {
+			return false, err
+		}
+		if !info.ModTime().Equal(c.modTimes[f]) {
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+func (c *CertReloader) reload() (bool, error) {
+	if changed, err := c.changed(); err != nil || !changed {
+		return false, err
+	}
+	modTimes := map[string]time.Time{}
+	for _, f := range []string{c.certFile, c.keyFile, c.caFile} {
+		if f == "" {
+			continue
+		}
+		info, err := os.Stat(f)
+		if err != nil {
+			return false, err
+		}
+		modTimes[f] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to load key pair %s, %s: %v", c.certFile, c.keyFile, err)
+	}
+	var clientCAs *x509.CertPool
+	if c.caFile != "" {
+		b, readErr := os.ReadFile(c.caFile)
+		if readErr != nil {
+			return false, fmt.Errorf("failed to read client CA bundle %s: %v", c.caFile, readErr)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(b) {
+			return false, fmt.Errorf("no certificates found in client CA bundle %s", c.caFile)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cert = &cert
+	c.clientCAs = clientCAs
+	c.modTimes = modTimes
+	return true, nil
+}
@@ -0,0 +1,91 @@
+//go:build unittests
+// +build unittests
+
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKeyPair(t *testing.T, certFile, keyFile, cn string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	assert.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	assert.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+// servedCommonName completes a handshake with the server and returns the common name of its certificate
+func servedCommonName(t *testing.T, addr string) string {
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true}) //nolint:gosec
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	now := time.Now()
+	writeKeyPair(t, certFile, keyFile, "first", now.Add(-time.Minute))
+
+	c, err := NewCertReloader(certFile, keyFile, "")
+	assert.NoError(t, err)
+	// the webhook is served by ListenAndServeTLS without certificate files
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := &http.Server{TLSConfig: c.TLSConfig(), ReadHeaderTimeout: time.Second}
+	served := make(chan error, 1)
+	go func() { served <- server.ServeTLS(l, "", "") }()
+	defer func() {
+		server.Close()
+		assert.ErrorIs(t, <-served, http.ErrServerClosed)
+	}()
+	addr := l.Addr().String()
+	assert.Equal(t, "first", servedCommonName(t, addr))
+
+	reloaded, err := c.reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+
+	writeKeyPair(t, certFile, keyFile, "second", now)
+	reloaded, err = c.reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, "second", servedCommonName(t, addr))
+
+	// a broken key pair keeps the current certificate
+	assert.NoError(t, os.WriteFile(keyFile, []byte("garbage"), 0600))
+	_, err = c.reload()
+	assert.Error(t, err)
+	assert.Equal(t, "second", servedCommonName(t, addr))
+}
@@ -18,7 +18,7 @@ DESCRIPTOR = _descriptor.FileDescriptor(
   package='pb',
   syntax='proto3',
   serialized_options=b'Z;github.com/redhat-cne/cloud-event-proxy/plugins/hw_event/pb',
-  serialized_pb=b'\n\x14message_parser.proto\x12\x02pb\"9\n\rParserRequest\x12\x12\n\nmessage_id\x18\x01 \x01(\t\x12\x14\n\x0cmessage_args\x18\x02 \x03(\t\"G\n\x0eParserResponse\x12\x0f\n\x07message\x18\x01 \x01(\t\x12\x10\n\x08severity\x18\x02 \x01(\t\x12\x12\n\nresolution\x18\x03 \x01(\t2A\n\rMessageParser\x12\x30\n\x05Parse\x12\x11.pb.ParserRequest\x1a\x12.pb.ParserResponse\"\x00\x42=Z;github.com/redhat-cne/cloud-event-proxy/plugins/hw_event/pbb\x06proto3'
+  serialized_pb=b'\n\x14message_parser.proto\x12\x02pb\"9\n\rParserRequest\x12\x12\n\nmessage_id\x18\x01 \x01(\t\x12\x14\n\x0cmessage_args\x18\x02 \x03(\t\"\xe7\x01\n\x0eParserResponse\x12\x0f\n\x07message\x18\x01 \x01(\t\x12\x10\n\x08severity\x18\x02 \x01(\t\x12\x12\n\nresolution\x18\x03 \x01(\t\x12\x18\n\x10registry_version\x18\x04 \x01(\t\x12\x17\n\x0fregistry_prefix\x18\x05 \x01(\t\x12\x13\n\x0bmessage_key\x18\x06 \x01(\t\x12\x16\n\x0enumber_of_args\x18\x07 \x01(\x05\x12\x13\n\x0bparam_types\x18\x08 \x03(\t\x12)\n\x0e\x63learing_logic\x18\t \x01(\x0b\x32\x11.pb.ClearingLogic\"N\n\rClearingLogic\x12\x12\n\nclears_all\x18\x01 \x01(\x08\x12\x11\n\tclears_if\x18\x02 \x01(\t\x12\x16\n\x0e\x63lears_message\x18\x03 \x03(\t\"8\n\x11ParseBatchRequest\x12#\n\x08requests\x18\x01 \x03(\x0b\x32\x11.pb.ParserRequest\"P\n\x0bParseResult\x12$\n\x08response\x18\x01 \x01(\x0b\x32\x12.pb.ParserResponse\x12\r\n\x05\x65rror\x18\x02 \x01(\t\x12\x0c\n\x04\x63ode\x18\x03 \x01(\x05\"6\n\x12ParseBatchResponse\x12 \n\x07results\x18\x01 \x03(\x0b\x32\x0f.pb.ParseResult\"\x17\n\x15ListRegistriesRequest\"\x84\x01\n\x0fRegistrySummary\x12\x17\n\x0fregistry_prefix\x18\x01 \x01(\t\x12\x18\n\x10registry_version\x18\x02 \x01(\t\x12\x10\n\x08language\x18\x03 \x01(\t\x12\x15\n\rowning_entity\x18\x04 \x01(\t\x12\x15\n\rmessage_count\x18\x05 \x01(\x05\"A\n\x16ListRegistriesResponse\x12\'\n\nregistries\x18\x01 \x03(\x0b\x32\x13.pb.RegistrySummary2\xcb\x01\n\rMessageParser\x12\x30\n\x05Parse\x12\x11.pb.ParserRequest\x1a\x12.pb.ParserResponse\"\x00\x12=\n\nParseBatch\x12\x15.pb.ParseBatchRequest\x1a\x16.pb.ParseBatchResponse\"\x00\x12I\n\x0eListRegistries\x12\x19.pb.ListRegistriesRequest\x1a\x1a.pb.ListRegistriesResponse\"\x00\x42=Z;github.com/redhat-cne/cloud-event-proxy/plugins/hw_event/pbb\x06proto3'
 )
 
 
@@ -90,6 +90,93 @@ _PARSERRESPONSE = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='registry_version', full_name='pb.ParserResponse.registry_version', index=3,
+      number=4, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=b"".decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='registry_prefix', full_name='pb.ParserResponse.registry_prefix', index=4,
+      number=5, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=b"".decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='message_key', full_name='pb.ParserResponse.message_key', index=5,
+      number=6, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=b"".decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='number_of_args', full_name='pb.ParserResponse.number_of_args', index=6,
+      number=7, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='param_types', full_name='pb.ParserResponse.param_types', index=7,
+      number=8, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='clearing_logic', full_name='pb.ParserResponse.clearing_logic', index=8,
+      number=9, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=88,
+  serialized_end=319,
+)
+
+
+_CLEARINGLOGIC = _descriptor.Descriptor(
+  name='ClearingLogic',
+  full_name='pb.ClearingLogic',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='clears_all', full_name='pb.ClearingLogic.clears_all', index=0,
+      number=1, type=8, cpp_type=7, label=1,
+      has_default_value=False, default_value=False,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='clears_if', full_name='pb.ClearingLogic.clears_if', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=b"".decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='clears_message', full_name='pb.ClearingLogic.clears_message', index=2,
+      number=3, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -102,12 +189,245 @@ _PARSERRESPONSE = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=87,
-  serialized_end=158,
+  serialized_start=321,
+  serialized_end=399,
 )
 
+
+_PARSEBATCHREQUEST = _descriptor.Descriptor(
+  name='ParseBatchRequest',
+  full_name='pb.ParseBatchRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='requests', full_name='pb.ParseBatchRequest.requests', index=0,
+      number=1, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=401,
+  serialized_end=457,
+)
+
+
+_PARSERESULT = _descriptor.Descriptor(
+  name='ParseResult',
+  full_name='pb.ParseResult',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='response', full_name='pb.ParseResult.response', index=0,
+      number=1, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='error', full_name='pb.ParseResult.error', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=b"".decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='code', full_name='pb.ParseResult.code', index=2,
+      number=3, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=459,
+  serialized_end=539,
+)
+
+
+_PARSEBATCHRESPONSE = _descriptor.Descriptor(
+  name='ParseBatchResponse',
+  full_name='pb.ParseBatchResponse',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='results', full_name='pb.ParseBatchResponse.results', index=0,
+      number=1, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=541,
+  serialized_end=595,
+)
+
+
+_LISTREGISTRIESREQUEST = _descriptor.Descriptor(
+  name='ListRegistriesRequest',
+  full_name='pb.ListRegistriesRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=597,
+  serialized_end=620,
+)
+
+
+_REGISTRYSUMMARY = _descriptor.Descriptor(
+  name='RegistrySummary',
+  full_name='pb.RegistrySummary',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='registry_prefix', full_name='pb.RegistrySummary.registry_prefix', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=b"".decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='registry_version', full_name='pb.RegistrySummary.registry_version', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=b"".decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='language', full_name='pb.RegistrySummary.language', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=b"".decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='owning_entity', full_name='pb.RegistrySummary.owning_entity', index=3,
+      number=4, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=b"".decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='message_count', full_name='pb.RegistrySummary.message_count', index=4,
+      number=5, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=623,
+  serialized_end=755,
+)
+
+
+_LISTREGISTRIESRESPONSE = _descriptor.Descriptor(
+  name='ListRegistriesResponse',
+  full_name='pb.ListRegistriesResponse',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='registries', full_name='pb.ListRegistriesResponse.registries', index=0,
+      number=1, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=757,
+  serialized_end=822,
+)
+
+_PARSERRESPONSE.fields_by_name['clearing_logic'].message_type = _CLEARINGLOGIC
+_PARSEBATCHREQUEST.fields_by_name['requests'].message_type = _PARSERREQUEST
+_PARSERESULT.fields_by_name['response'].message_type = _PARSERRESPONSE
+_PARSEBATCHRESPONSE.fields_by_name['results'].message_type = _PARSERESULT
+_LISTREGISTRIESRESPONSE.fields_by_name['registries'].message_type = _REGISTRYSUMMARY
 DESCRIPTOR.message_types_by_name['ParserRequest'] = _PARSERREQUEST
 DESCRIPTOR.message_types_by_name['ParserResponse'] = _PARSERRESPONSE
+DESCRIPTOR.message_types_by_name['ClearingLogic'] = _CLEARINGLOGIC
+DESCRIPTOR.message_types_by_name['ParseBatchRequest'] = _PARSEBATCHREQUEST
+DESCRIPTOR.message_types_by_name['ParseResult'] = _PARSERESULT
+DESCRIPTOR.message_types_by_name['ParseBatchResponse'] = _PARSEBATCHRESPONSE
+DESCRIPTOR.message_types_by_name['ListRegistriesRequest'] = _LISTREGISTRIESREQUEST
+DESCRIPTOR.message_types_by_name['RegistrySummary'] = _REGISTRYSUMMARY
+DESCRIPTOR.message_types_by_name['ListRegistriesResponse'] = _LISTREGISTRIESRESPONSE
 _sym_db.RegisterFileDescriptor(DESCRIPTOR)
 
 ParserRequest = _reflection.GeneratedProtocolMessageType('ParserRequest', (_message.Message,), {
@@ -124,6 +444,55 @@ ParserResponse = _reflection.GeneratedProtocolMessageType('ParserResponse', (_me
   })
 _sym_db.RegisterMessage(ParserResponse)
 
+ClearingLogic = _reflection.GeneratedProtocolMessageType('ClearingLogic', (_message.Message,), {
+  'DESCRIPTOR' : _CLEARINGLOGIC,
+  '__module__' : 'message_parser_pb2'
+  # @@protoc_insertion_point(class_scope:pb.ClearingLogic)
+  })
+_sym_db.RegisterMessage(ClearingLogic)
+
+ParseBatchRequest = _reflection.GeneratedProtocolMessageType('ParseBatchRequest', (_message.Message,), {
+  'DESCRIPTOR' : _PARSEBATCHREQUEST,
+  '__module__' : 'message_parser_pb2'
+  # @@protoc_insertion_point(class_scope:pb.ParseBatchRequest)
+  })
+_sym_db.RegisterMessage(ParseBatchRequest)
+
+ParseResult = _reflection.GeneratedProtocolMessageType('ParseResult', (_message.Message,), {
+  'DESCRIPTOR' : _PARSERESULT,
+  '__module__' : 'message_parser_pb2'
+  # @@protoc_insertion_point(class_scope:pb.ParseResult)
+  })
+_sym_db.RegisterMessage(ParseResult)
+
+ParseBatchResponse = _reflection.GeneratedProtocolMessageType('ParseBatchResponse', (_message.Message,), {
+  'DESCRIPTOR' : _PARSEBATCHRESPONSE,
+  '__module__' : 'message_parser_pb2'
+  # @@protoc_insertion_point(class_scope:pb.ParseBatchResponse)
+  })
+_sym_db.RegisterMessage(ParseBatchResponse)
+
+ListRegistriesRequest = _reflection.GeneratedProtocolMessageType('ListRegistriesRequest', (_message.Message,), {
+  'DESCRIPTOR' : _LISTREGISTRIESREQUEST,
+  '__module__' : 'message_parser_pb2'
+  # @@protoc_insertion_point(class_scope:pb.ListRegistriesRequest)
+  })
+_sym_db.RegisterMessage(ListRegistriesRequest)
+
+RegistrySummary = _reflection.GeneratedProtocolMessageType('RegistrySummary', (_message.Message,), {
+  'DESCRIPTOR' : _REGISTRYSUMMARY,
+  '__module__' : 'message_parser_pb2'
+  # @@protoc_insertion_point(class_scope:pb.RegistrySummary)
+  })
+_sym_db.RegisterMessage(RegistrySummary)
+
+ListRegistriesResponse = _reflection.GeneratedProtocolMessageType('ListRegistriesResponse', (_message.Message,), {
+  'DESCRIPTOR' : _LISTREGISTRIESRESPONSE,
+  '__module__' : 'message_parser_pb2'
+  # @@protoc_insertion_point(class_scope:pb.ListRegistriesResponse)
+  })
+_sym_db.RegisterMessage(ListRegistriesResponse)
+
 
 DESCRIPTOR._options = None
 
@@ -133,8 +502,8 @@ _MESSAGEPARSER = _descriptor.ServiceDescriptor(
   file=DESCRIPTOR,
   index=0,
   serialized_options=None,
-  serialized_start=160,
-  serialized_end=225,
+  serialized_start=825,
+  serialized_end=1028,
   methods=[
   _descriptor.MethodDescriptor(
     name='Parse',
@@ -145,6 +514,24 @@ _MESSAGEPARSER = _descriptor.ServiceDescriptor(
     output_type=_PARSERRESPONSE,
     serialized_options=None,
   ),
+  _descriptor.MethodDescriptor(
+    name='ParseBatch',
+    full_name='pb.MessageParser.ParseBatch',
+    index=1,
+    containing_service=None,
+    input_type=_PARSEBATCHREQUEST,
+    output_type=_PARSEBATCHRESPONSE,
+    serialized_options=None,
+  ),
+  _descriptor.MethodDescriptor(
+    name='ListRegistries',
+    full_name='pb.MessageParser.ListRegistries',
+    index=2,
+    containing_service=None,
+    input_type=_LISTREGISTRIESREQUEST,
+    output_type=_LISTREGISTRIESRESPONSE,
+    serialized_options=None,
+  ),
 ])
 _sym_db.RegisterServiceDescriptor(_MESSAGEPARSER)
 
@@ -18,6 +18,16 @@ class MessageParserStub(object):
                 request_serializer=message__parser__pb2.ParserRequest.SerializeToString,
                 response_deserializer=message__parser__pb2.ParserResponse.FromString,
                 )
+        self.ParseBatch = channel.unary_unary(
+                '/pb.MessageParser/ParseBatch',
+                request_serializer=message__parser__pb2.ParseBatchRequest.SerializeToString,
+                response_deserializer=message__parser__pb2.ParseBatchResponse.FromString,
+                )
+        self.ListRegistries = channel.unary_unary(
+                '/pb.MessageParser/ListRegistries',
+                request_serializer=message__parser__pb2.ListRegistriesRequest.SerializeToString,
+                response_deserializer=message__parser__pb2.ListRegistriesResponse.FromString,
+                )
 
 
 class MessageParserServicer(object):
@@ -29,6 +39,18 @@ class MessageParserServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def ParseBatch(self, request, context):
+        """Missing associated documentation comment in .proto file"""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListRegistries(self, request, context):
+        """Missing associated documentation comment in .proto file"""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_MessageParserServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -37,6 +59,16 @@ def add_MessageParserServicer_to_server(servicer, server):
                     request_deserializer=message__parser__pb2.ParserRequest.FromString,
                     response_serializer=message__parser__pb2.ParserResponse.SerializeToString,
             ),
+            'ParseBatch': grpc.unary_unary_rpc_method_handler(
+                    servicer.ParseBatch,
+                    request_deserializer=message__parser__pb2.ParseBatchRequest.FromString,
+                    response_serializer=message__parser__pb2.ParseBatchResponse.SerializeToString,
+            ),
+            'ListRegistries': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListRegistries,
+                    request_deserializer=message__parser__pb2.ListRegistriesRequest.FromString,
+                    response_serializer=message__parser__pb2.ListRegistriesResponse.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'pb.MessageParser', rpc_method_handlers)
@@ -62,3 +94,35 @@ class MessageParser(object):
             message__parser__pb2.ParserResponse.FromString,
             options, channel_credentials,
             call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ParseBatch(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.MessageParser/ParseBatch',
+            message__parser__pb2.ParseBatchRequest.SerializeToString,
+            message__parser__pb2.ParseBatchResponse.FromString,
+            options, channel_credentials,
+            call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListRegistries(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pb.MessageParser/ListRegistries',
+            message__parser__pb2.ListRegistriesRequest.SerializeToString,
+            message__parser__pb2.ListRegistriesResponse.FromString,
+            options, channel_credentials,
+            call_credentials, compression, wait_for_ready, timeout, metadata)
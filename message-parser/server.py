@@ -2,13 +2,17 @@ import logging
 from concurrent.futures import ThreadPoolExecutor
 
 import grpc
+from grpc_health.v1 import health, health_pb2, health_pb2_grpc
 
-from message_parser_pb2 import ParserResponse
+from message_parser_pb2 import (ClearingLogic, ListRegistriesResponse, ParseBatchResponse, ParseResult, ParserResponse,
+        RegistrySummary)
 from message_parser_pb2_grpc import MessageParserServicer, add_MessageParserServicer_to_server
 
+import json
 import os
 import sushy
-import sys
+import tarfile
+import time
 from sushy import auth
 from sushy.resources import base
 from sushy.resources import constants
@@ -19,6 +23,9 @@ import urllib3
 urllib3.disable_warnings(urllib3.exceptions.InsecureRequestWarning)
 
 MSG_PARSER_PORT = 9097
+SERVICE_NAME = 'pb.MessageParser'
+# in seconds
+REGISTRY_RETRY_INTERVAL = 60
 
 def get_log_level(level):
     level = level.upper()
@@ -36,57 +43,340 @@ def get_log_level(level):
         logging.warning('Log level %s is not supported. Set level to DEBUG.', level)
         return logging.DEBUG
 
+def registry_json(registry):
+    """Returns the JSON document of a registry"""
+    return getattr(registry, 'json', None) or getattr(registry, '_json', None) or {}
+
+def registry_metadata(registry, key):
+    """Returns the ParserResponse fields identifying the registry message, read from the registry JSON"""
+    message = registry_json(registry).get('Messages', {}).get(key, {})
+    metadata = {
+        'registry_prefix': registry.registry_prefix,
+        'registry_version': registry.registry_version,
+        'message_key': key,
+        'param_types': [str(t) for t in message.get('ParamTypes') or []],
+    }
+    if 'NumberOfArgs' in message:
+        metadata['number_of_args'] = int(message['NumberOfArgs'])
+    clearing_logic = message.get('ClearingLogic')
+    if clearing_logic:
+        metadata['clearing_logic'] = ClearingLogic(
+            clears_all=bool(clearing_logic.get('ClearsAll')),
+            clears_if=clearing_logic.get('ClearsIf') or '',
+            clears_message=clearing_logic.get('ClearsMessage') or [])
+    return metadata
+
+def registry_key(doc):
+    """Returns the key of a registry used in MessageIds, e.g. Base.1.8 for Base.1.8.1"""
+    return '%s.%s' % (doc['RegistryPrefix'], '.'.join(doc['RegistryVersion'].split('.')[:2]))
+
+def read_bundle(path):
+    """Returns the message registry JSON documents of a bundle, a directory, a tar or tar.gz archive or a single file"""
+    docs = []
+
+    def add(name, data):
+        try:
+            doc = json.loads(data)
+        except ValueError as e:
+            logging.warning('skipped %s of the registry bundle: %s', name, e)
+            return
+        if isinstance(doc, dict) and 'MessageRegistry.' in doc.get('@odata.type', '') \
+                and doc.get('RegistryPrefix') and doc.get('RegistryVersion'):
+            docs.append(doc)
+
+    if os.path.isdir(path):
+        for root, _, files in os.walk(path):
+            for name in sorted(files):
+                if name.endswith('.json'):
+                    with open(os.path.join(root, name), 'rb') as f:
+                        add(name, f.read())
+    elif tarfile.is_tarfile(path):
+        with tarfile.open(path) as tar:
+            for member in tar.getmembers():
+                if member.isfile() and member.name.endswith('.json'):
+                    add(member.name, tar.extractfile(member).read())
+    else:
+        with open(path, 'rb') as f:
+            add(path, f.read())
+    return docs
+
+class BundleReader(base.AbstractDataReader):
+    """Reads a message registry from its JSON document instead of the BMC"""
+
+    def __init__(self, doc):
+        self._doc = doc
+
+    def get_data(self):
+        return base.FieldData(200, {}, self._doc)
+
+def new_registry(doc):
+    """Returns the message registry of a JSON document"""
+    return message_registry.MessageRegistry(None, doc.get('@odata.id', ''), reader=BundleReader(doc))
+
+def load_bundle(path):
+    """Returns the message registries of a bundle by registry key"""
+    return {registry_key(doc): new_registry(doc) for doc in read_bundle(path)}
+
+def write_file(path, data):
+    """Replaces a file atomically"""
+    tmp = path + '.tmp'
+    with open(tmp, 'w') as f:
+        f.write(data)
+    os.replace(tmp, path)
+
+class RegistryCache:
+    """Persists the registries downloaded from the BMC with the UUID of its Redfish service, so that a restart
+    serves them without preloading the registries of the BMC again"""
+
+    INDEX = 'index.json'
+
+    def __init__(self, path):
+        self.path = path
+
+    def index(self):
+        try:
+            with open(os.path.join(self.path, self.INDEX)) as f:
+                return json.load(f)
+        except FileNotFoundError:
+            return {}
+        except (OSError, ValueError) as e:
+            logging.warning('Failed to read the registry cache index: %s', e)
+            return {}
+
+    def load(self, identity):
+        """Returns the cached registries by registry key, only when they were downloaded from the BMC with identity"""
+        index = self.index()
+        if not index.get('identity'):
+            return {}
+        if index['identity'] != identity:
+            logging.info('Ignored the cached Redfish Registries of BMC %s, the BMC is %s', index['identity'], identity)
+            return {}
+        registries = {}
+        for key in index.get('versions', {}):
+            try:
+                with open(os.path.join(self.path, key + '.json'), 'rb') as f:
+                    registries[key] = new_registry(json.load(f))
+            except (OSError, ValueError) as e:
+                logging.warning('Skipped cached registry %s: %s', key, e)
+        return registries
+
+    def save(self, identity, registries):
+        """Replaces the cached registries and logs the registry versions changed since the last download"""
+        docs = {}
+        for registry in registries.values():
+            doc = registry_json(registry)
+            if doc.get('RegistryPrefix') and doc.get('RegistryVersion'):
+                docs[registry_key(doc)] = doc
+        versions = {key: doc['RegistryVersion'] for key, doc in docs.items()}
+        previous = self.index()
+        if previous.get('identity') == identity:
+            previous_versions = previous.get('versions', {})
+            for key in sorted(set(previous_versions) | set(versions)):
+                before, after = previous_versions.get(key), versions.get(key)
+                if before is None:
+                    logging.info('BMC added registry %s %s', key, after)
+                elif after is None:
+                    logging.info('BMC removed registry %s %s', key, before)
+                elif before != after:
+                    logging.info('BMC changed registry %s from %s to %s', key, before, after)
+        elif previous.get('identity'):
+            logging.info('BMC identity changed from %s to %s, replacing the cached registries', previous['identity'], identity)
+
+        os.makedirs(self.path, exist_ok=True)
+        for key, doc in docs.items():
+            write_file(os.path.join(self.path, key + '.json'), json.dumps(doc))
+        write_file(os.path.join(self.path, self.INDEX), json.dumps({'identity': identity, 'versions': versions}))
+        for name in os.listdir(self.path):
+            if name.endswith('.json') and name != self.INDEX and name[:-len('.json')] not in versions:
+                os.remove(os.path.join(self.path, name))
+
+class ParseError(Exception):
+    """A message that can not be parsed, code is the gRPC status code returned"""
+
+    def __init__(self, code, details):
+        super().__init__(details)
+        self.code = code
+        self.details = details
+
 class MessageParserServicer(MessageParserServicer):
 
     def __init__(self):
-        redfish_username = os.environ.get('REDFISH_USERNAME')
-        redfish_password = os.environ.get('REDFISH_PASSWORD')
+        self.registries = None
+
+    def load_registries(self, health_servicer):
+        """Loads the registries of the bundle, then the registries of the BMC, which are retried until the BMC
+        is reachable. The parser is serving once registries are loaded."""
+        registries = {}
+        bundle_path = os.environ.get('MSG_PARSER_REGISTRY_PATH')
+        if bundle_path:
+            try:
+                registries = load_bundle(bundle_path)
+                logging.info('Loaded %d Redfish Registries from %s', len(registries), bundle_path)
+            except (OSError, tarfile.TarError) as e:
+                logging.error('Failed to load the registry bundle %s: %s', bundle_path, e)
+            if registries:
+                self.registries = registries
+                health_servicer.set(SERVICE_NAME, health_pb2.HealthCheckResponse.SERVING)
+
         redfish_hostaddr = os.environ.get('REDFISH_HOSTADDR')
+        if not redfish_hostaddr:
+            logging.warning('REDFISH_HOSTADDR is not set, the registries of the BMC are not loaded')
+            return
+        retry_interval = int(os.environ.get('MSG_PARSER_RETRY_INTERVAL', REGISTRY_RETRY_INTERVAL))
+        sushy_root = self.retry(lambda: self.connect(redfish_hostaddr), redfish_hostaddr, retry_interval)
 
-        basic_auth = auth.BasicAuth(username=redfish_username, password=redfish_password)
+        # the cached registries are only served when they were downloaded from this BMC
+        identity = sushy_root.uuid or redfish_hostaddr
+        cache = RegistryCache(os.path.join(os.environ.get('HW_EVENT_STORE_PATH', '/store'), 'message-parser', 'registries'))
+        cached = cache.load(identity)
+        if cached:
+            self.registries = {**registries, **cached}
+            health_servicer.set(SERVICE_NAME, health_pb2.HealthCheckResponse.SERVING)
+            logging.info('Loaded %d cached Redfish Registries of BMC %s', len(cached), identity)
+
+        bmc_registries = self.retry(lambda: self.preload(sushy_root), redfish_hostaddr, retry_interval)
+        # the registries of the BMC replace the same versions of the bundle
+        self.registries = {**registries, **bmc_registries}
+        health_servicer.set(SERVICE_NAME, health_pb2.HealthCheckResponse.SERVING)
         try:
-            self.sushy_root = sushy.Sushy('https://' + redfish_hostaddr + '/redfish/v1',
-                    auth=basic_auth, verify=False)
-        except sushy.exceptions.ConnectionError:
-            logging.error('Timeout connecting to %s', redfish_hostaddr)
-            sys.exit(1)
+            cache.save(identity, bmc_registries)
+        except OSError as e:
+            logging.error('Failed to cache the Redfish Registries: %s', e)
 
-        logging.info('Redfish version: %s', self.sushy_root.redfish_version)
-        self.registries = self.sushy_root.lazy_registries
+    @staticmethod
+    def retry(load, redfish_hostaddr, retry_interval):
+        """Calls load until the BMC is reachable"""
+        while True:
+            try:
+                return load()
+            except sushy.exceptions.SushyError as e:
+                logging.error('Failed to load the Redfish Registries of %s, retrying in %d seconds: %s',
+                        redfish_hostaddr, retry_interval, e)
+            time.sleep(retry_interval)
 
-        # preload the registries
-        logging.info('Preloading Redfish Registries...')
-        try:
-            self.registries.registries
-        except sushy.exceptions.AccessError as e:
-            logging.error(e)
-            sys.exit(1)
+    @staticmethod
+    def connect(redfish_hostaddr):
+        """Returns the service root of the BMC"""
+        redfish_username = os.environ.get('REDFISH_USERNAME')
+        redfish_password = os.environ.get('REDFISH_PASSWORD')
 
+        basic_auth = auth.BasicAuth(username=redfish_username, password=redfish_password)
+        sushy_root = sushy.Sushy('https://' + redfish_hostaddr + '/redfish/v1',
+                auth=basic_auth, verify=False)
+        logging.info('Redfish version: %s', sushy_root.redfish_version)
+        return sushy_root
+
+    @staticmethod
+    def preload(sushy_root):
+        """Returns the registries of the BMC by registry key"""
+        logging.info('Preloading Redfish Registries...')
+        registries = dict(sushy_root.lazy_registries.registries)
         logging.info('Preloading Redfish Registries DONE')
-    
+        return registries
+
     def Parse(self, request, context):
         logging.debug('request message_id: %s', request.message_id)
+        if self.registries is None:
+            context.abort(grpc.StatusCode.UNAVAILABLE, 'Redfish Registries are not loaded')
 
-        m = base.MessageListField('Message')
-        m.message_id = request.message_id
-        m.message_args = request.message_args
-        m.severity = None
-        m.resolution = None
-        m.message = None
+        try:
+            resp = self.parse(request)
+        except ParseError as e:
+            context.abort(e.code, e.details)
+        logging.debug('resp: %s', resp)
+        return resp
 
-        m_parsed = message_registry.parse_message(self.registries, m)
+    def ParseBatch(self, request, context):
+        logging.debug('batch of %d requests', len(request.requests))
+        if self.registries is None:
+            context.abort(grpc.StatusCode.UNAVAILABLE, 'Redfish Registries are not loaded')
+
+        results = []
+        for r in request.requests:
+            try:
+                results.append(ParseResult(response=self.parse(r)))
+            except ParseError as e:
+                results.append(ParseResult(code=e.code.value[0], error=e.details))
+            except Exception as e:
+                logging.error('failed to parse message %s: %s', r.message_id, e)
+                results.append(ParseResult(code=grpc.StatusCode.INTERNAL.value[0], error=str(e)))
+        return ParseBatchResponse(results=results)
+
+    def ListRegistries(self, request, context):
+        if self.registries is None:
+            context.abort(grpc.StatusCode.UNAVAILABLE, 'Redfish Registries are not loaded')
+
+        summaries = []
+        for registry in self.unique_registries():
+            summaries.append(RegistrySummary(
+                registry_prefix=getattr(registry, 'registry_prefix', None) or '',
+                registry_version=getattr(registry, 'registry_version', None) or '',
+                language=getattr(registry, 'language', None) or '',
+                owning_entity=getattr(registry, 'owning_entity', None) or '',
+                message_count=len(getattr(registry, 'messages', None) or {})))
+        summaries.sort(key=lambda s: (s.registry_prefix, s.registry_version))
+        return ListRegistriesResponse(registries=summaries)
+
+    def unique_registries(self):
+        """Returns the loaded registries, a registry of the BMC may be loaded under several keys"""
+        registries = {}
+        for registry in (self.registries or {}).values():
+            registries[id(registry)] = registry
+        return list(registries.values())
+
+    def find_registry(self, message_id):
+        """Returns the registry holding a message, None if it is not found. A MessageId without
+        registry, such as TMP0100 sent by iDRAC, is looked up in all the registries."""
+        parts = message_id.split('.')
+        prefix, version, key = parts[0], '.'.join(parts[1:-1]), parts[-1]
+        if len(parts) == 1:
+            prefix = None
+        for registry in self.unique_registries():
+            if prefix is not None and getattr(registry, 'registry_prefix', None) != prefix:
+                continue
+            if not str(getattr(registry, 'registry_version', '')).startswith(version):
+                continue
+            if key in (getattr(registry, 'messages', None) or {}):
+                return registry
+        return None
+
+    def parse(self, request):
+        """Parses a message, ParseError is raised for unknown messages and invalid arguments"""
+        metadata = {}
+        registry = self.find_registry(request.message_id)
+        if registry is not None:
+            metadata = registry_metadata(registry, request.message_id.split('.')[-1])
+            number_of_args = metadata.get('number_of_args')
+            if number_of_args is not None and len(request.message_args) != number_of_args:
+                raise ParseError(grpc.StatusCode.INVALID_ARGUMENT, 'message %s takes %d arguments, got %d' %
+                        (request.message_id, number_of_args, len(request.message_args)))
+
+        m_parsed = self.parse_message(request.message_id, request.message_args)
+        if m_parsed.message == 'unknown' and registry is not None and '.' not in request.message_id:
+            # sushy only looks up a MessageId without registry in the Messages and BaseMessages registries
+            version = '.'.join(str(registry.registry_version).split('.')[:2])
+            m_parsed = self.parse_message('%s.%s.%s' % (registry.registry_prefix, version, request.message_id),
+                    request.message_args)
 
         # Unable to find message for registry
         if m_parsed.message == 'unknown':
-            m_parsed.severity = 'unknown'
-            m_parsed.resolution = 'unknown'
+            raise ParseError(grpc.StatusCode.NOT_FOUND,
+                    'unable to find message %s in Redfish Registries' % request.message_id)
 
         if isinstance(m_parsed.severity, constants.Health):
             m_parsed.severity = m_parsed.severity.value
 
-        resp = ParserResponse(message=m_parsed.message, severity=m_parsed.severity, resolution=m_parsed.resolution)
-        logging.debug('resp: %s', resp)
-        return resp
+        return ParserResponse(message=m_parsed.message, severity=m_parsed.severity, resolution=m_parsed.resolution,
+                **metadata)
+
+    def parse_message(self, message_id, message_args):
+        m = base.MessageListField('Message')
+        m.message_id = message_id
+        m.message_args = message_args
+        m.severity = None
+        m.resolution = None
+        m.message = None
+        return message_registry.parse_message(self.registries, m)
 
 if __name__ == '__main__':
     l = os.environ.get('LOG_LEVEL', 'DEBUG')
@@ -101,12 +391,22 @@ if __name__ == '__main__':
     LOG.addHandler(logging.StreamHandler())
 
     server = grpc.server(ThreadPoolExecutor())
-    add_MessageParserServicer_to_server(MessageParserServicer(), server)
+    servicer = MessageParserServicer()
+    add_MessageParserServicer_to_server(servicer, server)
+    # the parser is reported as serving with the standard gRPC health checking protocol
+    # once the registries are loaded
+    health_servicer = health.HealthServicer()
+    health_pb2_grpc.add_HealthServicer_to_server(health_servicer, server)
+    health_servicer.set(SERVICE_NAME, health_pb2.HealthCheckResponse.NOT_SERVING)
 
     msg_parser_port = os.environ.get('MSG_PARSER_PORT')
     port = msg_parser_port if msg_parser_port else MSG_PARSER_PORT
     server.add_insecure_port(f'[::]:{port}')
     server.start()
+    logging.info('server started on port %r', port)
+
+    # the parser keeps running while the BMC is not reachable and reports NOT_SERVING until registries are loaded
+    servicer.load_registries(health_servicer)
     logging.info('server ready on port %r', port)
     server.wait_for_termination()
 
@@ -1,10 +1,51 @@
+import json
+import os
+import tempfile
 import unittest
-from server import MessageParserServicer
+from types import SimpleNamespace
+from server import MessageParserServicer, RegistryCache, read_bundle, registry_key
+
+TESTDATA = os.path.join(os.path.dirname(__file__), '..', 'hw-event-proxy', 'registry', 'testdata')
 
 
 class TestParse(unittest.TestCase):
     def test_parse(self):
         pass
 
+    def test_find_registry(self):
+        servicer = MessageParserServicer()
+        idrac = SimpleNamespace(registry_prefix='IDRAC', registry_version='2.8.0', messages={'TMP0100': {}})
+        base = SimpleNamespace(registry_prefix='Base', registry_version='1.8.1', messages={'AccessDenied': {}})
+        servicer.registries = {'IDRAC.2.8': idrac, 'Messages': idrac, 'Base.1.8': base}
+        self.assertIs(idrac, servicer.find_registry('IDRAC.2.8.TMP0100'))
+        # iDRAC sends MessageIds without registry
+        self.assertIs(idrac, servicer.find_registry('TMP0100'))
+        self.assertIsNone(servicer.find_registry('Base.1.8.TMP0100'))
+        self.assertIsNone(servicer.find_registry('FAN0001'))
+
+
+class TestBundle(unittest.TestCase):
+    def test_read_bundle(self):
+        docs = read_bundle(TESTDATA)
+        self.assertEqual(['Base.1.4', 'Base.1.8', 'IDRAC.2.8'], sorted(registry_key(d) for d in docs))
+
+    def test_read_file(self):
+        docs = read_bundle(os.path.join(TESTDATA, 'IDRAC.2.8.json'))
+        self.assertEqual(['IDRAC.2.8'], [registry_key(d) for d in docs])
+
+
+class TestRegistryCache(unittest.TestCase):
+    def test_cache(self):
+        with open(os.path.join(TESTDATA, 'IDRAC.2.8.json')) as f:
+            idrac = SimpleNamespace(json=json.load(f))
+        with tempfile.TemporaryDirectory() as path:
+            cache = RegistryCache(path)
+            self.assertEqual({}, cache.load('uuid-1'))
+            # the registries of the BMC may be listed under several keys
+            cache.save('uuid-1', {'IDRAC.2.8': idrac, 'Messages': idrac})
+            self.assertEqual(['IDRAC.2.8'], list(cache.load('uuid-1')))
+            # the cache was downloaded from another BMC
+            self.assertEqual({}, cache.load('uuid-2'))
+
 if __name__ == '__main__':
     unittest.main()